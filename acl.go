@@ -0,0 +1,204 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"aiven/terraform/governance/compliance/checker/internal/terraform"
+)
+
+// Kafka ACLs grant access to topics without an aiven_governance_access, so the owners of the topics an ACL grants
+// access to approve it like they approve an access. An aiven_kafka_acl selects topics with a pattern where * and ?
+// are wildcards, an aiven_kafka_native_acl selects them by name, a literal * selecting every topic, or by prefix.
+
+var ruleACLApproval = registerRule(Rule{
+	ID:          "AKG009-acl-approval",
+	Description: "owners of the topics a Kafka ACL grants access to approved it",
+	Remediation: "Request a review from a member of the owner group of each topic the ACL grants access to",
+})
+
+const (
+	resourceTypeTopic   = "Topic"
+	patternTypeLiteral  = "LITERAL"
+	patternTypePrefixed = "PREFIXED"
+	permissionTypeDeny  = "DENY"
+	// patternTypeWildcard is the pattern type of the topic of an aiven_kafka_acl, where * and ? are wildcards
+	patternTypeWildcard = "WILDCARD"
+	// wildcardResourceName is the resource name of a literal Kafka ACL for every resource of its type
+	wildcardResourceName = "*"
+)
+
+// kafkaACLCheck requires an approval from the owner group of each topic of the plan the ACL grants access to, like
+// for an aiven_governance_access the ACL doesn't grant access to every topic and matches a topic to ask the owners of
+func kafkaACLCheck(
+	resourceChange terraform.ResourceChange,
+	_ *terraform.PriorStateResource,
+	approvals Approvals,
+	plan *terraform.Plan,
+) CheckResult {
+	checkResult := CheckResult{ok: true, errors: []ResultError{}}
+
+	switch resourceChange.Change.Kind() {
+	case terraform.CreateChange, terraform.UpdateChange, terraform.ReplaceChange:
+	case terraform.DeleteChange, terraform.ForgetChange:
+		// Removing an ACL only takes access away, the owners don't need to approve it
+		return checkResult
+	case terraform.NoOpChange, terraform.ReadChange:
+		// Nothing changes, nothing to approve
+		return checkResult
+	}
+
+	if hasUnknownTopics(resourceChange) {
+		// The ACL may grant access to any topic, the owners of the topics can't be asked
+		project, service := resourceLocation(resourceChange)
+		checkResult.add([]ResultError{newUnknownACLTopicsError(resourceChange.Address, project, service)}, nil)
+		return checkResult
+	}
+
+	grant, ok := kafkaACLGrant(resourceChange)
+	if !ok {
+		return checkResult
+	}
+
+	checkResult.add(validateAccessACLs(resourceChange.Address, nil, []accessGrant{grant}, plan), nil)
+	checkResult.add(validateTopicOwnerApprovals(
+		resourceChange.Address, nil, findACLTopics(resourceChange, plan), approvals, plan, newACLApproveError,
+	))
+	return checkResult
+}
+
+// findACLTopics finds the topics with an owner group the ACL grants access to, in the project and service of the ACL
+func findACLTopics(resourceChange terraform.ResourceChange, plan *terraform.Plan) []terraform.ResourceChange {
+	grant, ok := kafkaACLGrant(resourceChange)
+	if !ok {
+		return nil
+	}
+
+	topics := []terraform.ResourceChange{}
+	for _, topic := range findAccessACLTopics(grant.accessData, grant.acl, findTopics(plan)) {
+		if hasOwnerGroup(topic) {
+			topics = append(topics, topic)
+		}
+	}
+	return topics
+}
+
+// hasUnknownTopics reports whether the topics the ACL selects are only known after apply, for example when its topic
+// refers to another resource. A native ACL of a known resource type other than Topic selects no topics.
+func hasUnknownTopics(resourceChange terraform.ResourceChange) bool {
+	after, unknown := resourceChange.Change.After, resourceChange.Change.AfterUnknown
+	if after == nil {
+		return false
+	}
+	isUnknown := func(value *string, afterUnknown *bool) bool {
+		return value == nil || (afterUnknown != nil && *afterUnknown)
+	}
+
+	switch resourceChange.Type {
+	case terraform.AivenKafkaACL:
+		return isUnknown(after.Topic, unknown.Topic)
+	case terraform.AivenKafkaNativeACL:
+		if isUnknown(after.ResourceType, unknown.ResourceType) {
+			return true
+		}
+		return isTopicResourceType(*after.ResourceType) && isUnknown(after.ResourceName, unknown.ResourceName)
+	}
+	return false
+}
+
+// kafkaACLGrant returns the ACL after the change as the ACL of an access, so that the topics are selected like the
+// topics of an aiven_governance_access, false if the ACL is deleted or doesn't select resources by name
+func kafkaACLGrant(resourceChange terraform.ResourceChange) (accessGrant, bool) {
+	after := resourceChange.Change.After
+	if after == nil {
+		return accessGrant{}, false
+	}
+
+	var acl terraform.AccessACL
+	switch resourceChange.Type {
+	case terraform.AivenKafkaACL:
+		if after.Topic == nil {
+			return accessGrant{}, false
+		}
+		acl = terraform.AccessACL{
+			ResourceName: *after.Topic, ResourceType: resourceTypeTopic, PatternType: patternTypeWildcard,
+		}
+	case terraform.AivenKafkaNativeACL:
+		if after.ResourceName == nil || after.ResourceType == nil {
+			return accessGrant{}, false
+		}
+		// ACLs of consumer groups, transactional IDs or the cluster, and denying ACLs, don't grant access to topics
+		acl = terraform.AccessACL{ResourceName: *after.ResourceName, ResourceType: *after.ResourceType}
+		if after.PatternType != nil {
+			acl.PatternType = *after.PatternType
+		}
+		if after.PermissionType != nil {
+			acl.PermissionType = *after.PermissionType
+		}
+	default:
+		return accessGrant{}, false
+	}
+
+	project, service := valuesLocation(after)
+	return accessGrant{accessData: terraform.AccessData{Project: project, ServiceName: service}, acl: acl}, true
+}
+
+// matchesACLTopicPattern reports whether the topic matches the topic pattern of an aiven_kafka_acl
+func matchesACLTopicPattern(pattern, topicName string) bool {
+	matched, err := path.Match(pattern, topicName)
+	return err == nil && matched
+}
+
+// matchesResourcePattern reports whether the topic matches the resource name of a Kafka native ACL with the pattern
+// type, an unknown pattern type matches every topic so that the owners of all of them are asked
+func matchesResourcePattern(patternType, resourceName, topicName string) bool {
+	switch patternType {
+	case patternTypeLiteral:
+		return resourceName == wildcardResourceName || resourceName == topicName
+	case patternTypePrefixed:
+		return strings.HasPrefix(topicName, resourceName)
+	case patternTypeWildcard:
+		return matchesACLTopicPattern(resourceName, topicName)
+	}
+	return true
+}
+
+// isUnboundedPattern reports whether the resource name of a Kafka ACL with the pattern type matches every resource,
+// including the ones created later
+func isUnboundedPattern(patternType, resourceName string) bool {
+	switch patternType {
+	case patternTypeLiteral:
+		return resourceName == wildcardResourceName
+	case patternTypePrefixed:
+		return resourceName == ""
+	case patternTypeWildcard:
+		// * matches any topic name and ? any character, a pattern of wildcards only matches nearly every topic
+		return strings.Trim(resourceName, "*?") == ""
+	}
+	return true
+}
+
+// normalizePatternType returns the pattern type of a Kafka ACL in upper case, LITERAL if it is not set
+func normalizePatternType(patternType string) string {
+	if patternType == "" {
+		return patternTypeLiteral
+	}
+	return strings.ToUpper(patternType)
+}
+
+func isTopicResourceType(resourceType string) bool {
+	return strings.EqualFold(resourceType, resourceTypeTopic)
+}
+
+func newACLApproveError(address string, topicAddress string) ResultError {
+	err := fmt.Sprintf("approval is required from an owner of %s", topicAddress)
+	return newResultError(ruleACLApproval, err, address, nil, SeverityError)
+}
+
+func newUnknownACLTopicsError(address string, project string, service string) ResultError {
+	err := fmt.Sprintf("the topics of the ACL are known after apply only, it may grant access to every topic of %s/%s",
+		project, service,
+	)
+	return newResultError(ruleUnboundedAccess, err, address, nil, SeverityError)
+}
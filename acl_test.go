@@ -0,0 +1,185 @@
+package main
+
+import (
+	"testing"
+
+	"aiven/terraform/governance/compliance/checker/internal/terraform"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnit_MatchesACLTopicPattern(t *testing.T) {
+	tests := []struct {
+		pattern   string
+		topicName string
+		matches   bool
+	}{
+		{pattern: "orders", topicName: "orders", matches: true},
+		{pattern: "orders", topicName: "orders-dlq", matches: false},
+		{pattern: "*", topicName: "orders", matches: true},
+		{pattern: "pay*", topicName: "payments-refunds", matches: true},
+		{pattern: "order?", topicName: "orders", matches: true},
+		{pattern: "[", topicName: "orders", matches: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.topicName, func(t *testing.T) {
+			assert.Equal(t, tt.matches, matchesACLTopicPattern(tt.pattern, tt.topicName))
+		})
+	}
+}
+
+func TestUnit_MatchesResourcePattern(t *testing.T) {
+	tests := []struct {
+		patternType  string
+		resourceName string
+		topicName    string
+		matches      bool
+	}{
+		{patternType: patternTypeLiteral, resourceName: "orders", topicName: "orders", matches: true},
+		{patternType: patternTypeLiteral, resourceName: "ord", topicName: "orders", matches: false},
+		{patternType: patternTypeLiteral, resourceName: "*", topicName: "orders", matches: true},
+		{patternType: patternTypePrefixed, resourceName: "ord", topicName: "orders", matches: true},
+		{patternType: patternTypePrefixed, resourceName: "pay", topicName: "orders", matches: false},
+		{patternType: patternTypeWildcard, resourceName: "pay*", topicName: "payments", matches: true},
+		{patternType: patternTypeWildcard, resourceName: "pay*", topicName: "orders", matches: false},
+		{patternType: "MATCH", resourceName: "pay", topicName: "orders", matches: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.patternType+" "+tt.resourceName+" "+tt.topicName, func(t *testing.T) {
+			assert.Equal(t, tt.matches, matchesResourcePattern(tt.patternType, tt.resourceName, tt.topicName))
+		})
+	}
+}
+
+func TestUnit_IsUnboundedPattern(t *testing.T) {
+	tests := []struct {
+		patternType  string
+		resourceName string
+		unbounded    bool
+	}{
+		{patternType: patternTypeLiteral, resourceName: "*", unbounded: true},
+		{patternType: patternTypeLiteral, resourceName: "orders", unbounded: false},
+		{patternType: patternTypePrefixed, resourceName: "", unbounded: true},
+		{patternType: patternTypePrefixed, resourceName: "orders.", unbounded: false},
+		{patternType: patternTypeWildcard, resourceName: "*", unbounded: true},
+		{patternType: patternTypeWildcard, resourceName: "**", unbounded: true},
+		{patternType: patternTypeWildcard, resourceName: "?*", unbounded: true},
+		{patternType: patternTypeWildcard, resourceName: "orders*", unbounded: false},
+		{patternType: "MATCH", resourceName: "orders", unbounded: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.patternType+" "+tt.resourceName, func(t *testing.T) {
+			assert.Equal(t, tt.unbounded, isUnboundedPattern(tt.patternType, tt.resourceName))
+		})
+	}
+}
+
+func TestUnit_HasUnknownTopics(t *testing.T) {
+	topic, group := resourceTypeTopic, "Group"
+	afterApply, inPlan := true, false
+	tests := []struct {
+		name         string
+		resourceType terraform.ResourceType
+		after        *terraform.ResourceChangeValues
+		afterUnknown terraform.AfterUnknown
+		expected     bool
+	}{
+		{
+			name:         "Topic of an ACL known after apply",
+			resourceType: terraform.AivenKafkaACL,
+			after:        &terraform.ResourceChangeValues{},
+			afterUnknown: terraform.AfterUnknown{Topic: &afterApply},
+			expected:     true,
+		},
+		{
+			name:         "Topic of an ACL in the plan",
+			resourceType: terraform.AivenKafkaACL,
+			after:        &terraform.ResourceChangeValues{Topic: &topic},
+			afterUnknown: terraform.AfterUnknown{Topic: &inPlan},
+			expected:     false,
+		},
+		{
+			name:         "Resource name of a native ACL of topics known after apply",
+			resourceType: terraform.AivenKafkaNativeACL,
+			after:        &terraform.ResourceChangeValues{ResourceType: &topic},
+			expected:     true,
+		},
+		{
+			name:         "Resource type of a native ACL known after apply",
+			resourceType: terraform.AivenKafkaNativeACL,
+			after:        &terraform.ResourceChangeValues{ResourceName: &topic},
+			expected:     true,
+		},
+		{
+			name:         "Resource name of a native ACL of consumer groups known after apply",
+			resourceType: terraform.AivenKafkaNativeACL,
+			after:        &terraform.ResourceChangeValues{ResourceType: &group},
+			afterUnknown: terraform.AfterUnknown{ResourceName: &afterApply},
+			expected:     false,
+		},
+		{
+			name:         "Deleted ACL",
+			resourceType: terraform.AivenKafkaACL,
+			expected:     false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resourceChange := terraform.ResourceChange{
+				Type:   tt.resourceType,
+				Change: terraform.Change{After: tt.after, AfterUnknown: tt.afterUnknown},
+			}
+			assert.Equal(t, tt.expected, hasUnknownTopics(resourceChange))
+		})
+	}
+}
+
+func TestUnit_FindACLTopics(t *testing.T) {
+	plan := getTestPlan(t, "testdata/plan_with_acls.json")
+	addresses := func(topics []terraform.ResourceChange) []string {
+		result := []string{}
+		for _, topic := range topics {
+			result = append(result, topic.Address)
+		}
+		return result
+	}
+	find := func(address string) terraform.ResourceChange {
+		for _, resourceChange := range plan.ResourceChanges {
+			if resourceChange.Address == address {
+				return resourceChange
+			}
+		}
+		t.Fatalf("resource change %s is not in the plan", address)
+		return terraform.ResourceChange{}
+	}
+
+	t.Run("Finds the topics of the plan and the prior state matching the topic pattern", func(t *testing.T) {
+		assert.Equal(t,
+			[]string{"aiven_kafka_topic.payments", "data.aiven_kafka_topic.payments_refunds"},
+			addresses(findACLTopics(find("aiven_kafka_acl.payments"), plan)),
+		)
+	})
+
+	t.Run("Finds the topics matching the prefix of a native ACL", func(t *testing.T) {
+		assert.Equal(t,
+			[]string{"aiven_kafka_topic.orders"},
+			addresses(findACLTopics(find("aiven_kafka_native_acl.orders"), plan)),
+		)
+	})
+
+	t.Run("Finds no topics for a native ACL of another resource type", func(t *testing.T) {
+		assert.Empty(t, findACLTopics(find("aiven_kafka_native_acl.consumer_group"), plan))
+	})
+
+	t.Run("Finds no topics for a native ACL denying access", func(t *testing.T) {
+		assert.Empty(t, findACLTopics(find("aiven_kafka_native_acl.deny_all"), plan))
+	})
+
+	t.Run("Finds no topics for a deleted ACL", func(t *testing.T) {
+		assert.Empty(t, findACLTopics(find("aiven_kafka_acl.legacy"), plan))
+	})
+}
@@ -1,53 +1,148 @@
 package main
 
 import (
+	"aiven/terraform/governance/compliance/checker/internal/policy"
 	"aiven/terraform/governance/compliance/checker/internal/terraform"
 	"fmt"
 	"slices"
+	"strings"
+)
+
+var (
+	ruleRequesterOwner = registerRule(Rule{
+		ID:          "AKG001-requester-owner",
+		Description: "the requester is a member of the owner group of the resource",
+		Remediation: "Open the change as a member of the owner group, or ask the owner group to add you as a member",
+	})
+	ruleApprovalOwner = registerRule(Rule{
+		ID:          "AKG002-approval-owner",
+		Description: "a member of the owner group of the resource approved the change",
+		Remediation: "Request a review from a member of the owner group",
+	})
+	ruleOwnerUnresolved = registerRule(Rule{
+		ID:          "AKG003-owner-unresolved",
+		Description: "the owner group of the resource can be resolved from the configuration",
+		Remediation: "Refer to the owner group through a resource, input variable or module output instead of a local value",
+	})
+	ruleDestructiveChange = registerRule(Rule{
+		ID:          "AKG004-destructive-change",
+		Description: "a member of the owner group approved replacing the resource",
+		Remediation: "Avoid changing arguments that force a replacement, " +
+			"or request a review from a member of the owner group",
+	})
+	ruleGovernanceAccessApproval = registerRule(Rule{
+		ID:          "AKG005-governance-access-approval",
+		Description: "owners of the topics an aiven_governance_access grants access to approved it",
+		Remediation: "Request a review from a member of the owner group of each topic the access grants access to",
+	})
+	ruleRequiredTags = registerRule(Rule{
+		ID:          "AKG006-required-tags",
+		Description: "resources that are created or changed carry the required tag keys",
+		Remediation: "Add the missing tags to the resource",
+	})
+	ruleStaleApproval = registerRule(Rule{
+		ID:          "AKG007-stale-approval",
+		Description: "approvals are given for the latest commit of the change",
+		Remediation: "Ask the approver to review the latest commit again",
+	})
+	ruleSchemaSubjectTopic = registerRule(Rule{
+		ID:          "AKG008-schema-subject-topic",
+		Description: "the subject of a schema belongs to a topic of the configuration by the TopicNameStrategy",
+		Remediation: "Name the subject <topic>-key or <topic>-value after a topic managed in the configuration",
+	})
+	ruleUnboundedAccess = registerRule(Rule{
+		ID:          "AKG010-unbounded-access",
+		Description: "an access or a Kafka ACL doesn't grant access to every topic of a service",
+		Remediation: "Grant access to topics by name or by a non-empty prefix",
+	})
+	ruleAccessTopicNotFound = registerRule(Rule{
+		ID:          "AKG011-access-topic-not-found",
+		Description: "the topics an access or a Kafka ACL grants access to are found in the plan or the state",
+		Remediation: "Manage the topic in the configuration or read it with an aiven_kafka_topic data source",
+	})
+	ruleRequesterUnresolved = registerRule(Rule{
+		ID:          "AKG012-requester-unresolved",
+		Description: "the requester has an aiven_external_identity, checked in strict mode",
+		Remediation: "Import the aiven_external_identity of the requester, or map their login in the identities file",
+	})
 )
 
 type CheckResult struct {
 	ok     bool
 	errors []ResultError
+	// evidence records who satisfied the requirements of the check
+	evidence []Evidence
+}
+
+// Evidence is a requirement of a check and the requester or approver who satisfied it
+type Evidence struct {
+	Requirement string `json:"requirement"`
+	SatisfiedBy string `json:"satisfied_by"`
+}
+
+// add adds the errors and evidence of validating a requirement
+func (checkResult *CheckResult) add(resultErrors []ResultError, evidence []Evidence) {
+	checkResult.errors = append(checkResult.errors, resultErrors...)
+	checkResult.evidence = append(checkResult.evidence, evidence...)
+	if len(resultErrors) > 0 {
+		checkResult.ok = false
+	}
 }
 
 func changeIsRequestedByOwner(
 	resourceChange terraform.ResourceChange,
 	requester *terraform.PriorStateResource,
-	_ []*terraform.PriorStateResource,
+	_ Approvals,
 	plan *terraform.Plan,
 ) CheckResult {
+	if resourceChange.Type == terraform.AivenKafkaSchema {
+		return schemaChangeIsRequestedByOwner(resourceChange, requester, plan)
+	}
+
 	checkResult := CheckResult{ok: true, errors: []ResultError{}}
 
 	// If the owner is defined but it's a new group it's in the state post-apply so we have to use config to check it
 	if ownerAfterApply := resourceChange.Change.AfterUnknown.OwnerUserGroupID; ownerAfterApply != nil && *ownerAfterApply {
-		if !isUserGroupMemberInConfig(resourceChange, requester, plan) {
+		if _, err := findOwnerAddressFromConfig(resourceChange.Address, plan); err != nil {
 			checkResult.ok = false
 			checkResult.errors = append(checkResult.errors,
-				newRequestError(resourceChange.Address, resourceChange.Change.After.Tag),
+				newUnresolvedOwnerError(resourceChange.Address, resourceChange.Change.After.Tag, err),
 			)
+			return checkResult
 		}
 
-		// There is an error in validating topic owner so return the errors immediately
-		return checkResult
-	}
+		if !isUserGroupMemberInConfig(resourceChange, requester, plan) {
+			checkResult.ok = false
+			checkResult.errors = append(checkResult.errors,
+				newRequestError(resourceChange.Address, resourceChange.Change.After.Tag),
+			)
 
-	// When the resource is created, the requester must be a member of the owner group after the change
-	if slices.Contains(resourceChange.Change.Actions, terraform.CreateAction) {
-		checkResult.errors = append(checkResult.errors,
-			validateRequesterFromState(resourceChange.Address, resourceChange.Change.After, requester, plan)...)
-	}
-	// When the resource is updated, the requester must be a member of the owner group before and after the change
-	if slices.Contains(resourceChange.Change.Actions, terraform.UpdateAction) {
-		checkResult.errors = append(checkResult.errors,
-			validateRequesterFromState(resourceChange.Address, resourceChange.Change.Before, requester, plan)...)
-		checkResult.errors = append(checkResult.errors,
-			validateRequesterFromState(resourceChange.Address, resourceChange.Change.After, requester, plan)...)
-	}
-	// When the resource is deleted, the requester must be a member of the owner group before the change
-	if slices.Contains(resourceChange.Change.Actions, terraform.DeleteAction) {
-		checkResult.errors = append(checkResult.errors,
-			validateRequesterFromState(resourceChange.Address, resourceChange.Change.Before, requester, plan)...)
+			// There is an error in validating topic owner so return the errors immediately
+			return checkResult
+		}
+		checkResult.evidence = append(checkResult.evidence, newRequesterEvidence(
+			fmt.Sprintf("requester is a member of the owner group of %s", resourceChange.Address), requester,
+		))
+	}
+
+	address := resourceChange.Address
+	before, after := resourceChange.Change.Before, resourceChange.Change.After
+
+	switch resourceChange.Change.Kind() {
+	case terraform.CreateChange:
+		// When the resource is created, the requester must be a member of the owner group after the change
+		checkResult.add(validateRequesterFromState(address, after, requester, plan))
+	case terraform.UpdateChange, terraform.ReplaceChange:
+		// When the resource is updated or replaced, the requester must be a member of the owner group
+		// before and after the change
+		checkResult.add(validateRequesterFromState(address, before, requester, plan))
+		checkResult.add(validateRequesterFromState(address, after, requester, plan))
+	case terraform.DeleteChange, terraform.ForgetChange:
+		// When the resource is deleted or no longer managed, the requester must be a member of the owner group
+		// before the change
+		checkResult.add(validateRequesterFromState(address, before, requester, plan))
+	case terraform.NoOpChange, terraform.ReadChange:
+		// Nothing changes, nothing to request
 	}
 
 	if len(checkResult.errors) > 0 {
@@ -59,58 +154,89 @@ func changeIsRequestedByOwner(
 func changeIsApprovedByOwner(
 	resourceChange terraform.ResourceChange,
 	_ *terraform.PriorStateResource,
-	approvers []*terraform.PriorStateResource,
+	approvals Approvals,
 	plan *terraform.Plan,
 ) CheckResult {
+	if resourceChange.Type == terraform.AivenKafkaSchema {
+		return schemaChangeIsApprovedByOwner(resourceChange, approvals, plan)
+	}
+
 	checkResult := CheckResult{ok: true, errors: []ResultError{}}
 
 	// If the owner is defined but it's a new group it's in the state post-apply so we have to use config to check it
 	if ownerAfterApply := resourceChange.Change.AfterUnknown.OwnerUserGroupID; ownerAfterApply != nil && *ownerAfterApply {
-		foundApprover := false
-		for _, approver := range approvers {
+		if _, err := findOwnerAddressFromConfig(resourceChange.Address, plan); err != nil {
+			checkResult.ok = false
+			checkResult.errors = append(checkResult.errors,
+				newUnresolvedOwnerError(resourceChange.Address, resourceChange.Change.After.Tag, err),
+			)
+			return checkResult
+		}
+
+		var foundApprover *terraform.PriorStateResource
+		for _, approver := range approvals.Approvers {
 			if isUserGroupMemberInConfig(resourceChange, approver, plan) {
-				foundApprover = true // one known approver is enough
+				foundApprover = approver // one known approver is enough
+				break
 			}
 		}
 
-		if !foundApprover {
+		if foundApprover == nil {
 			checkResult.ok = false
+			approveError := newApproveError(resourceChange.Address, resourceChange.Change.After.Tag)
+			if resourceChange.Change.Kind() == terraform.ReplaceChange {
+				approveError = newDestructiveChangeError(resourceChange.Address, resourceChange.Change.After.Tag)
+			}
 			checkResult.errors = append(checkResult.errors,
-				newApproveError(resourceChange.Address, resourceChange.Change.After.Tag),
+				approveError.withOwnerGroup(findOwnerGroupInConfig(resourceChange.Address, plan)),
 			)
+			checkResult.errors = append(checkResult.errors, staleApprovalErrors(
+				resourceChange.Address, resourceChange.Change.After.Tag, approvals,
+				func(approver *terraform.PriorStateResource) bool {
+					return isUserGroupMemberInConfig(resourceChange, approver, plan)
+				},
+			)...)
 
 			// There is an error in validating topic owner so return the errors immediately
 			return checkResult
 		}
+		checkResult.evidence = append(checkResult.evidence, newRequesterEvidence(
+			fmt.Sprintf("approval from a member of the owner group of %s", resourceChange.Address), foundApprover,
+		))
 	}
 
-	// When the resource is created, the approvers must be a member of the owner group after the change
-	if slices.Contains(resourceChange.Change.Actions, terraform.CreateAction) {
-		checkResult.errors = append(
-			checkResult.errors,
-			validateApproversFromState(resourceChange.Address, resourceChange.Change.After, approvers, plan)...,
-		)
-	}
+	address := resourceChange.Address
+	before, after := resourceChange.Change.Before, resourceChange.Change.After
 
-	if slices.Contains(resourceChange.Change.Actions, terraform.UpdateAction) {
+	switch resourceChange.Change.Kind() {
+	case terraform.CreateChange:
+		// When the resource is created, the approvers must be a member of the owner group after the change
+		checkResult.add(validateApproversFromState(address, after, approvals, plan))
+	case terraform.UpdateChange:
 		// updating owner requires approvals from both old and the new owner
 		// in other cases checking Change.After would be redundant
-		checkResult.errors = append(
-			checkResult.errors,
-			validateApproversFromState(resourceChange.Address, resourceChange.Change.Before, approvers, plan)...,
-		)
-		checkResult.errors = append(
-			checkResult.errors,
-			validateApproversFromState(resourceChange.Address, resourceChange.Change.After, approvers, plan)...,
-		)
-	}
-
-	// When the resource is deleted, the approvers must be a member of the owner group before the change
-	if slices.Contains(resourceChange.Change.Actions, terraform.DeleteAction) {
-		checkResult.errors = append(
-			checkResult.errors,
-			validateApproversFromState(resourceChange.Address, resourceChange.Change.Before, approvers, plan)...,
-		)
+		checkResult.add(validateApproversFromState(address, before, approvals, plan))
+		checkResult.add(validateApproversFromState(address, after, approvals, plan))
+	case terraform.ReplaceChange:
+		// Replacing the resource destroys its data, so both the old and the new owner must approve it
+		// and missing approvals are reported as destructive changes
+		for _, values := range []*terraform.ResourceChangeValues{before, after} {
+			resultErrors, evidence := validateApproversFromState(address, values, approvals, plan)
+			destructiveErrors := make([]ResultError, 0, len(resultErrors))
+			for _, err := range resultErrors {
+				if err.RuleID == ruleApprovalOwner.ID {
+					err = newDestructiveChangeError(err.Address, &err.Tags).withOwnerGroup(err.OwnerGroup)
+				}
+				destructiveErrors = append(destructiveErrors, err)
+			}
+			checkResult.add(destructiveErrors, evidence)
+		}
+	case terraform.DeleteChange, terraform.ForgetChange:
+		// When the resource is deleted or no longer managed, the approvers must be a member of the owner group
+		// before the change
+		checkResult.add(validateApproversFromState(address, before, approvals, plan))
+	case terraform.NoOpChange, terraform.ReadChange:
+		// Nothing changes, nothing to approve
 	}
 
 	if len(checkResult.errors) > 0 {
@@ -119,6 +245,185 @@ func changeIsApprovedByOwner(
 	return checkResult
 }
 
+// A schema has no owner group of its own, it is owned by the owner group of the topic its subject belongs to.
+// Subjects follow the TopicNameStrategy of the schema registry: the schema of the keys of a topic is registered
+// as <topic>-key and the schema of its values as <topic>-value.
+
+func schemaChangeIsRequestedByOwner(
+	resourceChange terraform.ResourceChange,
+	requester *terraform.PriorStateResource,
+	plan *terraform.Plan,
+) CheckResult {
+	checkResult := CheckResult{ok: true, errors: []ResultError{}}
+
+	topics, resultErrors := findSchemaOwnerTopics(resourceChange, plan)
+	if len(resultErrors) > 0 {
+		checkResult.add(resultErrors, nil)
+		return checkResult
+	}
+
+	// The requester must be a member of the owner group of the topic of the subject before and after the change
+	for _, topic := range topics {
+		if !isTopicOwnerGroupMember(topic, requester, plan) {
+			checkResult.add([]ResultError{newRequestError(resourceChange.Address, nil)}, nil)
+			continue
+		}
+		checkResult.add(nil, []Evidence{newRequesterEvidence(
+			fmt.Sprintf("requester is a member of the owner group of %s", topic.Address), requester,
+		)})
+	}
+	return checkResult
+}
+
+func schemaChangeIsApprovedByOwner(
+	resourceChange terraform.ResourceChange,
+	approvals Approvals,
+	plan *terraform.Plan,
+) CheckResult {
+	checkResult := CheckResult{ok: true, errors: []ResultError{}}
+
+	topics, resultErrors := findSchemaOwnerTopics(resourceChange, plan)
+	if len(resultErrors) > 0 {
+		checkResult.add(resultErrors, nil)
+		return checkResult
+	}
+
+	// Replacing the schema deletes the subject, consumers can't read the records written with it anymore
+	newError := func(address string, _ string) ResultError { return newApproveError(address, nil) }
+	if resourceChange.Change.Kind() == terraform.ReplaceChange {
+		newError = func(address string, _ string) ResultError { return newDestructiveChangeError(address, nil) }
+	}
+	checkResult.add(validateTopicOwnerApprovals(resourceChange.Address, nil, topics, approvals, plan, newError))
+	return checkResult
+}
+
+// findSchemaOwnerTopics finds the topics owning the schema: the topic of the subject after the change, and before it
+// when the schema is updated, replaced or deleted, so that moving a schema to another subject needs the owners of
+// both. Topics without owner group are left out, as are all topics if nothing changes.
+func findSchemaOwnerTopics(
+	resourceChange terraform.ResourceChange,
+	plan *terraform.Plan,
+) ([]terraform.ResourceChange, []ResultError) {
+	var schemaValues []*terraform.ResourceChangeValues
+	before, after := resourceChange.Change.Before, resourceChange.Change.After
+	switch resourceChange.Change.Kind() {
+	case terraform.NoOpChange, terraform.ReadChange:
+		// Nothing changes, no owner needed
+		return nil, nil
+	case terraform.CreateChange:
+		schemaValues = []*terraform.ResourceChangeValues{after}
+	case terraform.UpdateChange, terraform.ReplaceChange:
+		schemaValues = []*terraform.ResourceChangeValues{before, after}
+	case terraform.DeleteChange, terraform.ForgetChange:
+		schemaValues = []*terraform.ResourceChangeValues{before}
+	}
+
+	topics, resultErrors := []terraform.ResourceChange{}, []ResultError{}
+	for _, values := range schemaValues {
+		topic, err := findSchemaTopic(values, plan)
+		if err != nil {
+			resultError := newSchemaSubjectTopicError(resourceChange.Address, err)
+			isSame := func(other ResultError) bool { return other.Error == resultError.Error }
+			if !slices.ContainsFunc(resultErrors, isSame) {
+				resultErrors = append(resultErrors, resultError)
+			}
+			continue
+		}
+
+		isSame := func(other terraform.ResourceChange) bool { return other.Address == topic.Address }
+		if hasOwnerGroup(*topic) && !slices.ContainsFunc(topics, isSame) {
+			topics = append(topics, *topic)
+		}
+	}
+	return topics, resultErrors
+}
+
+// hasOwnerGroup checks if the topic has an owner group, or gets one created by the plan
+func hasOwnerGroup(topic terraform.ResourceChange) bool {
+	if ownerUnknown := topic.Change.AfterUnknown.OwnerUserGroupID; ownerUnknown != nil && *ownerUnknown {
+		return true
+	}
+	owner := topicValues(topic).OwnerUserGroupID
+	return owner != nil && *owner != ""
+}
+
+// findSchemaTopic finds the topic the subject of the schema values belongs to in the same project and service
+func findSchemaTopic(values *terraform.ResourceChangeValues, plan *terraform.Plan) (*terraform.ResourceChange, error) {
+	if values == nil || values.SubjectName == nil {
+		return nil, fmt.Errorf("subject is not known")
+	}
+
+	subject := *values.SubjectName
+	topicName, ok := subjectTopicName(subject)
+	if !ok {
+		return nil, fmt.Errorf("subject %s is not named <topic>-key or <topic>-value", subject)
+	}
+
+	project, service := valuesLocation(values)
+	for _, topic := range findTopics(plan) {
+		topicProject, topicService := resourceLocation(topic)
+		if topicProject != project || topicService != service {
+			continue
+		}
+		if name := topicValues(topic).TopicName; name != nil && *name == topicName {
+			return &topic, nil
+		}
+	}
+	return nil, fmt.Errorf("topic %s of subject %s is not found in the plan or the state", topicName, subject)
+}
+
+// subjectTopicName returns the name of the topic of a subject named by the TopicNameStrategy
+func subjectTopicName(subject string) (string, bool) {
+	for _, suffix := range []string{"-key", "-value"} {
+		if topicName, ok := strings.CutSuffix(subject, suffix); ok && topicName != "" {
+			return topicName, true
+		}
+	}
+	return "", false
+}
+
+// topicValues returns the values of the topic after the change, or before it when the topic is deleted
+func topicValues(topic terraform.ResourceChange) *terraform.ResourceChangeValues {
+	if topic.Change.After != nil {
+		return topic.Change.After
+	}
+	if topic.Change.Before != nil {
+		return topic.Change.Before
+	}
+	return &terraform.ResourceChangeValues{}
+}
+
+// isTopicOwnerGroupMember checks if the user is a member of the owner group of the topic, in the configuration
+// if the owner group is created by the plan and in the state otherwise
+func isTopicOwnerGroupMember(
+	topic terraform.ResourceChange,
+	user *terraform.PriorStateResource,
+	plan *terraform.Plan,
+) bool {
+	if ownerUnknown := topic.Change.AfterUnknown.OwnerUserGroupID; ownerUnknown != nil && *ownerUnknown {
+		return isUserGroupMemberInConfig(topic, user, plan)
+	}
+	return isUserGroupMemberInState(topicValues(topic), user, plan)
+}
+
+// findTopicOwnerGroup finds the owner group of the topic and its members, like isTopicOwnerGroupMember
+func findTopicOwnerGroup(topic terraform.ResourceChange, plan *terraform.Plan) *OwnerGroup {
+	ownerUnknown := topic.Change.AfterUnknown.OwnerUserGroupID
+	if values := topicValues(topic); (ownerUnknown == nil || !*ownerUnknown) && values.OwnerUserGroupID != nil {
+		return findOwnerGroupInState(*values.OwnerUserGroupID, plan)
+	}
+	return findOwnerGroupInConfig(topic.Address, plan)
+}
+
+// grantsTopicAccess reports whether the ACL of an access allows access to topics, denying ACLs and ACLs of other
+// resource types don't need the approval of topic owners
+func grantsTopicAccess(acl terraform.AccessACL) bool {
+	if acl.ResourceType != "" && !isTopicResourceType(acl.ResourceType) {
+		return false
+	}
+	return !strings.EqualFold(acl.PermissionType, permissionTypeDeny)
+}
+
 func isAccessResource(
 	accessData terraform.AccessData,
 	acl terraform.AccessACL,
@@ -141,83 +446,182 @@ func isAccessResource(
 		return false
 	}
 
-	if after.TopicName != nil && *after.TopicName != acl.ResourceName {
+	if after.TopicName != nil &&
+		!matchesResourcePattern(normalizePatternType(acl.PatternType), acl.ResourceName, *after.TopicName) {
 		return false
 	}
 
 	return true
 }
 
-func getAccessResources(
-	resourceChange terraform.ResourceChange,
-	plan *terraform.Plan,
-) []terraform.ResourceChange {
-	resources := []terraform.ResourceChange{}
+// accessGrant is an ACL of an access with the access_data block, and so the project and service, it belongs to
+type accessGrant struct {
+	accessData terraform.AccessData
+	acl        terraform.AccessACL
+}
+
+func (grant accessGrant) equal(other accessGrant) bool {
+	return grant.accessData.Project == other.accessData.Project &&
+		grant.accessData.ServiceName == other.accessData.ServiceName && grant.acl == other.acl
+}
 
-	var accessData terraform.AccessData
-	if resourceChange.Change.After.AccessData != nil {
-		accessData = (*resourceChange.Change.After.AccessData)[0]
+// accessGrants lists the ACLs of every access_data block of the access, none if the values are not known
+func accessGrants(values *terraform.ResourceChangeValues) []accessGrant {
+	grants := []accessGrant{}
+	if values == nil || values.AccessData == nil {
+		return grants
 	}
+	for _, accessData := range *values.AccessData {
+		for _, acl := range accessData.Acls {
+			grants = append(grants, accessGrant{accessData: accessData, acl: acl})
+		}
+	}
+	return grants
+}
+
+// addedAccessGrants lists the ACLs the access has after the change but not before, removed ACLs only take access away
+func addedAccessGrants(resourceChange terraform.ResourceChange) []accessGrant {
+	before := accessGrants(resourceChange.Change.Before)
+	added := []accessGrant{}
+	for _, grant := range accessGrants(resourceChange.Change.After) {
+		if !slices.ContainsFunc(before, grant.equal) {
+			added = append(added, grant)
+		}
+	}
+	return added
+}
+
+// getAccessResources finds the topics of the plan and of the prior state the ACLs grant access to, each once
+func getAccessResources(grants []accessGrant, plan *terraform.Plan) []terraform.ResourceChange {
+	resources := []terraform.ResourceChange{}
 
-	for _, acl := range accessData.Acls {
-		for _, resource := range plan.ResourceChanges {
-			if isAccessResource(accessData, acl, resource) {
+	topics := findTopics(plan)
+	found := map[string]bool{}
+	for _, grant := range grants {
+		for _, resource := range findAccessACLTopics(grant.accessData, grant.acl, topics) {
+			if !found[resource.Address] {
 				resources = append(resources, resource)
+				found[resource.Address] = true
 			}
 		}
 	}
 	return resources
 }
 
+// findAccessACLTopics finds the topics the ACL of the access grants access to
+func findAccessACLTopics(
+	accessData terraform.AccessData,
+	acl terraform.AccessACL,
+	topics []terraform.ResourceChange,
+) []terraform.ResourceChange {
+	resources := []terraform.ResourceChange{}
+	if !grantsTopicAccess(acl) {
+		return resources
+	}
+	for _, resource := range topics {
+		if isAccessResource(accessData, acl, resource) {
+			resources = append(resources, resource)
+		}
+	}
+	return resources
+}
+
+// validateAccessACLs reports the ACLs granting access to every topic of a service, topics created later are covered
+// without their owners approving it, and the ACLs matching no topic of the plan or the prior state, whose owners
+// can't be asked
+func validateAccessACLs(
+	address string,
+	tag *[]terraform.Tag,
+	grants []accessGrant,
+	plan *terraform.Plan,
+) []ResultError {
+	resultErrors := []ResultError{}
+
+	topics := findTopics(plan)
+	for _, grant := range grants {
+		accessData, acl := grant.accessData, grant.acl
+		switch {
+		case !grantsTopicAccess(acl):
+		case isUnboundedPattern(normalizePatternType(acl.PatternType), acl.ResourceName):
+			resultErrors = append(resultErrors, newUnboundedAccessError(address, tag, accessData, acl))
+		case len(findAccessACLTopics(accessData, acl, topics)) == 0:
+			resultErrors = append(resultErrors, newAccessTopicNotFoundError(address, tag, accessData, acl))
+		}
+	}
+	return resultErrors
+}
+
+// governanceAccessCreateCheck requires an approval from the owner group of each topic the ACLs grant access to
 func governanceAccessCreateCheck(
 	resourceChange terraform.ResourceChange,
-	approvers []*terraform.PriorStateResource,
+	grants []accessGrant,
+	approvals Approvals,
 	plan *terraform.Plan,
 ) CheckResult {
-
 	checkResult := CheckResult{ok: true, errors: []ResultError{}}
 
+	var tag *[]terraform.Tag
+	if resourceChange.Change.After != nil {
+		tag = resourceChange.Change.After.Tag
+	}
+
+	if resourceChange.Change.AfterUnknown.AccessData {
+		// The access may grant access to any topic, the owners of the topics can't be asked
+		checkResult.add([]ResultError{newUnknownAccessDataError(resourceChange.Address, tag)}, nil)
+	}
+	checkResult.add(validateAccessACLs(resourceChange.Address, tag, grants, plan), nil)
+
 	// Check each access resource
-resources:
-	for _, resource := range getAccessResources(resourceChange, plan) {
-		ownerUnknown := resource.Change.AfterUnknown.OwnerUserGroupID != nil && *resource.Change.AfterUnknown.OwnerUserGroupID
-
-		// We need one approver to be a member the resource owner group
-		for _, approver := range approvers {
-			if ownerUnknown && isUserGroupMemberInConfig(resource, approver, plan) {
-				continue resources
-			}
-			if !ownerUnknown && isUserGroupMemberInState(resource.Change.After, approver, plan) {
-				continue resources
+	checkResult.add(validateTopicOwnerApprovals(
+		resourceChange.Address, tag, getAccessResources(grants, plan), approvals, plan, newGovernanceAccessApproveError,
+	))
+
+	return checkResult
+}
+
+// validateTopicOwnerApprovals requires an approval from a member of the owner group of each topic, a missing
+// approval is reported with newError next to the stale approvals of members
+func validateTopicOwnerApprovals(
+	address string,
+	tag *[]terraform.Tag,
+	topics []terraform.ResourceChange,
+	approvals Approvals,
+	plan *terraform.Plan,
+	newError func(address string, topicAddress string) ResultError,
+) ([]ResultError, []Evidence) {
+	resultErrors, evidence := []ResultError{}, []Evidence{}
+
+topics:
+	for _, topic := range topics {
+		isMember := func(approver *terraform.PriorStateResource) bool {
+			return isTopicOwnerGroupMember(topic, approver, plan)
+		}
+
+		// We need one approver to be a member the topic owner group
+		for _, approver := range approvals.Approvers {
+			if isMember(approver) {
+				evidence = append(evidence, newRequesterEvidence(
+					fmt.Sprintf("approval from a member of the owner group of %s", topic.Address), approver,
+				))
+				continue topics
 			}
 		}
 
 		// No approval found, add error
-		checkResult.errors = append(checkResult.errors, ResultError{
-			Error:   fmt.Sprintf("approval is required from a owner of %s", resource.Address),
-			Address: resourceChange.Address,
-		})
-
-	}
-
-	if len(checkResult.errors) > 0 {
-		checkResult.ok = false
+		resultErrors = append(resultErrors, newError(address, topic.Address).withOwnerGroup(findTopicOwnerGroup(topic, plan)))
+		resultErrors = append(resultErrors, staleApprovalErrors(address, tag, approvals, isMember)...)
 	}
-
-	return checkResult
+	return resultErrors, evidence
 }
 
 func governanceAccessDeleteCheck(
 	resourceChange terraform.ResourceChange,
-	approvers []*terraform.PriorStateResource,
+	approvals Approvals,
 	plan *terraform.Plan,
 ) CheckResult {
 	checkResult := CheckResult{ok: true, errors: []ResultError{}}
 
-	checkResult.errors = append(
-		checkResult.errors,
-		validateApproversFromState(resourceChange.Address, resourceChange.Change.Before, approvers, plan)...,
-	)
+	checkResult.add(validateApproversFromState(resourceChange.Address, resourceChange.Change.Before, approvals, plan))
 
 	if len(checkResult.errors) > 0 {
 		checkResult.ok = false
@@ -226,49 +630,152 @@ func governanceAccessDeleteCheck(
 	return checkResult
 }
 
+func mergeCheckResults(first, second CheckResult) CheckResult {
+	return CheckResult{
+		ok:       first.ok && second.ok,
+		errors:   append(first.errors, second.errors...),
+		evidence: append(first.evidence, second.evidence...),
+	}
+}
+
 func governanceAccessCheck(
 	resourceChange terraform.ResourceChange,
 	_ *terraform.PriorStateResource,
-	approvers []*terraform.PriorStateResource,
+	approvals Approvals,
 	plan *terraform.Plan,
 ) CheckResult {
-	// For create, approval is required from owners of the resources where the access grants access
-	if slices.Contains(resourceChange.Change.Actions, terraform.CreateAction) {
-		return governanceAccessCreateCheck(resourceChange, approvers, plan)
+	switch resourceChange.Change.Kind() {
+	case terraform.CreateChange:
+		// For create, approval is required from owners of the resources where the access grants access
+		return governanceAccessCreateCheck(resourceChange, accessGrants(resourceChange.Change.After), approvals, plan)
+	case terraform.ReplaceChange:
+		// The replacement is a new access, and removing the old one needs the approval of its owner
+		return mergeCheckResults(
+			governanceAccessCreateCheck(resourceChange, accessGrants(resourceChange.Change.After), approvals, plan),
+			governanceAccessDeleteCheck(resourceChange, approvals, plan),
+		)
+	case terraform.UpdateChange:
+		// Changing the access needs the approval of its owner, and the ACLs it didn't have before the approval of
+		// the owners of the topics they grant access to
+		return mergeCheckResults(
+			governanceAccessCreateCheck(resourceChange, addedAccessGrants(resourceChange), approvals, plan),
+			governanceAccessDeleteCheck(resourceChange, approvals, plan),
+		)
+	case terraform.DeleteChange, terraform.ForgetChange:
+		return governanceAccessDeleteCheck(resourceChange, approvals, plan)
+	case terraform.NoOpChange, terraform.ReadChange:
+		// Nothing changes, nothing to approve
 	}
 
-	return governanceAccessDeleteCheck(resourceChange, approvers, plan)
+	return CheckResult{ok: true, errors: []ResultError{}}
+}
+
+// Requires the resource to carry the tag keys listed in the comma separated keys parameter after the change
+func newRequiredTagsCheck(params policy.Params) (Check, error) {
+	keys := []string{}
+	for _, key := range strings.Split(params["keys"], ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("keys parameter is required")
+	}
+
+	return func(
+		resourceChange terraform.ResourceChange,
+		_ *terraform.PriorStateResource,
+		_ Approvals,
+		_ *terraform.Plan,
+	) CheckResult {
+		checkResult := CheckResult{ok: true, errors: []ResultError{}}
+
+		// only the resources being created or changed need to be tagged
+		switch resourceChange.Change.Kind() {
+		case terraform.CreateChange, terraform.UpdateChange, terraform.ReplaceChange:
+		case terraform.DeleteChange, terraform.ForgetChange, terraform.NoOpChange, terraform.ReadChange:
+			return checkResult
+		}
+
+		after := resourceChange.Change.After
+		if after == nil {
+			return checkResult
+		}
+
+		tags := []terraform.Tag{}
+		if after.Tag != nil {
+			tags = *after.Tag
+		}
+
+		missing := []string{}
+		for _, key := range keys {
+			if !slices.ContainsFunc(tags, func(tag terraform.Tag) bool { return tag.Key == key }) {
+				missing = append(missing, key)
+			}
+		}
+
+		if len(missing) > 0 {
+			checkResult.ok = false
+			checkResult.errors = append(checkResult.errors, newMissingTagsError(resourceChange.Address, after.Tag, missing))
+		}
+		return checkResult
+	}, nil
 }
 
 func validateApproversFromState(
 	address string,
 	resource *terraform.ResourceChangeValues,
-	approvers []*terraform.PriorStateResource,
+	approvals Approvals,
 	plan *terraform.Plan,
-) []ResultError {
+) ([]ResultError, []Evidence) {
 	resultErrors := []ResultError{}
 
 	// if the resource in state is missing or doesn't have an owner, return immediately
 	if resource == nil {
-		return resultErrors
+		return resultErrors, nil
 	}
 	if resource.OwnerUserGroupID == nil {
-		return resultErrors
+		return resultErrors, nil
 	}
 	if *resource.OwnerUserGroupID == "" {
-		return resultErrors
+		return resultErrors, nil
 	}
 
 	// At least one approver is required
-	for _, approver := range approvers {
+	for _, approver := range approvals.Approvers {
 		if isUserGroupMemberInState(resource, approver, plan) {
 			// found a member, short circuit the function
-			return resultErrors
+			requirement := fmt.Sprintf("approval from a member of the owner group %s", *resource.OwnerUserGroupID)
+			return resultErrors, []Evidence{newRequesterEvidence(requirement, approver)}
 		}
 	}
 
-	// did not find a member, add an approve error
-	resultErrors = append(resultErrors, newApproveError(address, resource.Tag))
+	// did not find a member, add an approve error naming the members who can approve
+	resultErrors = append(resultErrors,
+		newApproveError(address, resource.Tag).withOwnerGroup(findOwnerGroupInState(*resource.OwnerUserGroupID, plan)),
+	)
+	resultErrors = append(resultErrors, staleApprovalErrors(address, resource.Tag, approvals,
+		func(approver *terraform.PriorStateResource) bool {
+			return isUserGroupMemberInState(resource, approver, plan)
+		},
+	)...)
+	return resultErrors, nil
+}
+
+// staleApprovalErrors reports the stale approvals of members of the owner group, they would satisfy the approval
+// requirement if they were given for the latest commit
+func staleApprovalErrors(
+	address string,
+	tag *[]terraform.Tag,
+	approvals Approvals,
+	isMember func(approver *terraform.PriorStateResource) bool,
+) []ResultError {
+	resultErrors := []ResultError{}
+	for _, stale := range approvals.Stale {
+		if isMember(stale.Approver) {
+			resultErrors = append(resultErrors, newStaleApprovalError(address, tag, stale))
+		}
+	}
 	return resultErrors
 }
 
@@ -277,58 +784,157 @@ func validateRequesterFromState(
 	resource *terraform.ResourceChangeValues,
 	requester *terraform.PriorStateResource,
 	plan *terraform.Plan,
-) []ResultError {
+) ([]ResultError, []Evidence) {
 	resultErrors := []ResultError{}
 
 	// if the resource in state is missing or doesn't have an owner, return immediately
 	if resource == nil {
-		return resultErrors
+		return resultErrors, nil
 	}
 	if resource.OwnerUserGroupID == nil {
-		return resultErrors
+		return resultErrors, nil
 	}
 	if *resource.OwnerUserGroupID == "" {
-		return resultErrors
+		return resultErrors, nil
 	}
 
 	// Requester is required
 	if requester == nil || !isUserGroupMemberInState(resource, requester, plan) {
 		resultErrors = append(resultErrors, newRequestError(address, resource.Tag))
-		return resultErrors
+		return resultErrors, nil
 	}
 
-	// did not find any member, add an approve error
-	return resultErrors
+	requirement := fmt.Sprintf("requester is a member of the owner group %s", *resource.OwnerUserGroupID)
+	return resultErrors, []Evidence{newRequesterEvidence(requirement, requester)}
+}
+
+// newRequesterEvidence returns the evidence of a requirement satisfied by the requester or an approver
+func newRequesterEvidence(requirement string, user *terraform.PriorStateResource) Evidence {
+	return Evidence{Requirement: requirement, SatisfiedBy: user.Values.ExternalUserID}
 }
 
 func newRequestError(address string, tag *[]terraform.Tag) ResultError {
 	err := "requesting user is not a member of the owner group"
-	if tag != nil {
-		return ResultError{
-			Error:   err,
-			Address: address,
-			Tags:    *tag,
-		}
-	}
-	return ResultError{
-		Error:   err,
-		Address: address,
-		Tags:    []terraform.Tag{},
+	return newResultError(ruleRequesterOwner, err, address, tag, SeverityError)
+}
+
+// newRequesterUnresolvedError reports the requester without an identity, it concerns the change rather than a
+// resource, so it has no address
+func newRequesterUnresolvedError(requesterID string) ResultError {
+	err := fmt.Sprintf("requester %q has no aiven_external_identity", requesterID)
+	return newResultError(ruleRequesterUnresolved, err, "", nil, SeverityError)
+}
+
+func newDestructiveChangeError(address string, tag *[]terraform.Tag) ResultError {
+	err := "destructive change: replacing the resource deletes its data, " +
+		"approval is required from a member of the owner group"
+	return newResultError(ruleDestructiveChange, err, address, tag, SeverityError)
+}
+
+func newStaleApprovalError(address string, tag *[]terraform.Tag, stale StaleApproval) ResultError {
+	commit := stale.CommitID
+	if len(commit) > shortCommitLength {
+		commit = commit[:shortCommitLength]
 	}
+	err := fmt.Sprintf("approval by %s is stale (commit %s)", stale.Approver.Values.ExternalUserID, commit)
+	return newResultError(ruleStaleApproval, err, address, tag, SeverityError)
+}
+
+func newMissingTagsError(address string, tag *[]terraform.Tag, missing []string) ResultError {
+	err := fmt.Sprintf("required tags are missing: %s", strings.Join(missing, ", "))
+	return newResultError(ruleRequiredTags, err, address, tag, SeverityWarning)
 }
 
 func newApproveError(address string, tag *[]terraform.Tag) ResultError {
 	err := "approval is required from a member of the owner group"
+	return newResultError(ruleApprovalOwner, err, address, tag, SeverityError)
+}
+
+func newUnresolvedOwnerError(address string, tag *[]terraform.Tag, cause error) ResultError {
+	err := fmt.Sprintf("owner group can not be resolved from the configuration: %s", cause)
+	return newResultError(ruleOwnerUnresolved, err, address, tag, SeverityError)
+}
+
+func newSchemaSubjectTopicError(address string, cause error) ResultError {
+	err := fmt.Sprintf("topic of the schema can not be found: %s", cause)
+	return newResultError(ruleSchemaSubjectTopic, err, address, nil, SeverityError)
+}
+
+func newGovernanceAccessApproveError(address string, topicAddress string) ResultError {
+	err := fmt.Sprintf("approval is required from a owner of %s", topicAddress)
+	return newResultError(ruleGovernanceAccessApproval, err, address, nil, SeverityError)
+}
+
+func newUnboundedAccessError(
+	address string,
+	tag *[]terraform.Tag,
+	accessData terraform.AccessData,
+	acl terraform.AccessACL,
+) ResultError {
+	operation := acl.Operation
+	if operation == "" {
+		operation = "access"
+	}
+	err := fmt.Sprintf("%s pattern %q grants %s to every topic of %s/%s, including topics created later",
+		normalizePatternType(acl.PatternType), acl.ResourceName, operation, accessData.Project, accessData.ServiceName,
+	)
+	return newResultError(ruleUnboundedAccess, err, address, tag, SeverityError)
+}
+
+func newUnknownAccessDataError(address string, tag *[]terraform.Tag) ResultError {
+	err := "the access_data of the access is known after apply only, it may grant access to every topic"
+	return newResultError(ruleUnboundedAccess, err, address, tag, SeverityError)
+}
+
+func newAccessTopicNotFoundError(
+	address string,
+	tag *[]terraform.Tag,
+	accessData terraform.AccessData,
+	acl terraform.AccessACL,
+) ResultError {
+	err := fmt.Sprintf("no topic of %s/%s matching %s pattern %q is found in the plan or the state",
+		accessData.Project, accessData.ServiceName, normalizePatternType(acl.PatternType), acl.ResourceName,
+	)
+	return newResultError(ruleAccessTopicNotFound, err, address, tag, SeverityError)
+}
+
+// withOwnerGroup adds the owner group whose members can approve the change to an approval error
+func (err ResultError) withOwnerGroup(ownerGroup *OwnerGroup) ResultError {
+	err.OwnerGroup = ownerGroup
+	if ownerGroup != nil && len(ownerGroup.EligibleApprovers) > 0 {
+		err.Remediation = fmt.Sprintf("Request a review from one of: %s", strings.Join(ownerGroup.EligibleApprovers, ", "))
+	}
+	return err
+}
+
+// withoutApprovers removes the users who can't approve the change, like the requester, from the eligible approvers of
+// an approval error
+func (err ResultError) withoutApprovers(users []string) ResultError {
+	if err.OwnerGroup == nil || len(users) == 0 {
+		return err
+	}
+	ownerGroup := *err.OwnerGroup
+	ownerGroup.EligibleApprovers = slices.DeleteFunc(slices.Clone(ownerGroup.EligibleApprovers),
+		func(approver string) bool { return slices.Contains(users, approver) },
+	)
+	if len(ownerGroup.EligibleApprovers) == 0 {
+		err.Remediation = rules[err.RuleID].Remediation
+	}
+	return err.withOwnerGroup(&ownerGroup)
+}
+
+func newResultError(rule Rule, err string, address string, tag *[]terraform.Tag, severity Severity) ResultError {
+	tags := []terraform.Tag{}
 	if tag != nil {
-		return ResultError{
-			Error:   err,
-			Address: address,
-			Tags:    *tag,
-		}
+		tags = *tag
 	}
 	return ResultError{
-		Error:   err,
-		Address: address,
-		Tags:    []terraform.Tag{},
+		Error:       err,
+		Address:     address,
+		Tags:        tags,
+		Severity:    severity,
+		RuleID:      rule.ID,
+		Remediation: rule.Remediation,
+		DocsURL:     rule.DocsURL(),
 	}
 }
@@ -49,13 +49,62 @@ func TestUnit_ValidateApproversFromStateSimpleCases(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			resultErrors := validateApproversFromState(tt.address, tt.resource, tt.approvers, tt.plan)
+			resultErrors, _ := validateApproversFromState(tt.address, tt.resource, Approvals{Approvers: tt.approvers}, tt.plan)
 			if len(resultErrors) != tt.expectedErrors {
 				t.Errorf("expected %d errors, got %d", tt.expectedErrors, len(resultErrors))
 			}
 		})
 	}
 }
+
+func TestUnit_ValidateApproversFromStateStaleApprovals(t *testing.T) {
+	plan := getTestPlan(t, "testdata/plan_with_known_owner_user_group_id.json")
+	resource := &terraform.ResourceChangeValues{OwnerUserGroupID: stringPtr("ug4e3b20cee48")}
+	stale := StaleApproval{Approver: findExternalIdentity("bob", plan), CommitID: "abc1234f5e6d7c8b9a0f"}
+
+	t.Run("Reports the stale approvals of members of the owner group", func(t *testing.T) {
+		resultErrors, _ := validateApproversFromState("aiven_kafka_topic.foo", resource, Approvals{
+			Approvers: []*terraform.PriorStateResource{findExternalIdentity("charlie", plan)},
+			Stale:     []StaleApproval{stale},
+		}, plan)
+		assert.Len(t, resultErrors, 2)
+		assert.Equal(t, ruleApprovalOwner.ID, resultErrors[0].RuleID)
+		assert.Equal(t, newStaleApprovalError("aiven_kafka_topic.foo", resource.Tag, stale), resultErrors[1])
+	})
+
+	t.Run("Ignores stale approvals if a member approved the latest commit", func(t *testing.T) {
+		resultErrors, _ := validateApproversFromState("aiven_kafka_topic.foo", resource, Approvals{
+			Approvers: []*terraform.PriorStateResource{findExternalIdentity("alice", plan)},
+			Stale:     []StaleApproval{stale},
+		}, plan)
+		assert.Empty(t, resultErrors)
+	})
+
+	t.Run("Ignores stale approvals of users outside of the owner group", func(t *testing.T) {
+		resultErrors, _ := validateApproversFromState("aiven_kafka_topic.foo", resource, Approvals{
+			Stale: []StaleApproval{{Approver: findExternalIdentity("charlie", plan), CommitID: "abc1234"}},
+		}, plan)
+		assert.Len(t, resultErrors, 1)
+		assert.Equal(t, ruleApprovalOwner.ID, resultErrors[0].RuleID)
+	})
+}
+
+func TestUnit_NewStaleApprovalError(t *testing.T) {
+	approver := &terraform.PriorStateResource{Values: terraform.PriorStateResourceValues{ExternalUserID: "bob"}}
+
+	t.Run("Abbreviates the commit", func(t *testing.T) {
+		err := newStaleApprovalError("resource1", nil, StaleApproval{Approver: approver, CommitID: "abc1234f5e6d7c8b9a0f"})
+		assert.Equal(t, "approval by bob is stale (commit abc1234)", err.Error)
+		assert.Equal(t, ruleStaleApproval.ID, err.RuleID)
+		assert.Equal(t, SeverityError, err.Severity)
+	})
+
+	t.Run("Keeps short commits", func(t *testing.T) {
+		err := newStaleApprovalError("resource1", nil, StaleApproval{Approver: approver, CommitID: "abc123"})
+		assert.Equal(t, "approval by bob is stale (commit abc123)", err.Error)
+	})
+}
+
 func TestUnit_ValidateRequesterFromStateSimpleCases(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -98,7 +147,7 @@ func TestUnit_ValidateRequesterFromStateSimpleCases(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			resultErrors := validateRequesterFromState(tt.address, tt.resource, tt.requester, tt.plan)
+			resultErrors, _ := validateRequesterFromState(tt.address, tt.resource, tt.requester, tt.plan)
 			if len(resultErrors) != tt.expectedErrors {
 				t.Errorf("expected %d errors, got %d", tt.expectedErrors, len(resultErrors))
 			}
@@ -118,9 +167,13 @@ func TestUnit_NewRequestError(t *testing.T) {
 			address: "resource1",
 			tag:     []terraform.Tag{{Key: "env", Value: "prod"}},
 			expected: ResultError{
-				Error:   "requesting user is not a member of the owner group",
-				Address: "resource1",
-				Tags:    []terraform.Tag{{Key: "env", Value: "prod"}},
+				Error:       "requesting user is not a member of the owner group",
+				Address:     "resource1",
+				Tags:        []terraform.Tag{{Key: "env", Value: "prod"}},
+				Severity:    SeverityError,
+				RuleID:      ruleRequesterOwner.ID,
+				Remediation: ruleRequesterOwner.Remediation,
+				DocsURL:     ruleRequesterOwner.DocsURL(),
 			},
 		},
 		{
@@ -128,9 +181,13 @@ func TestUnit_NewRequestError(t *testing.T) {
 			address: "resource2",
 			tag:     []terraform.Tag{{Key: "env", Value: "prod"}, {Key: "team", Value: "devops"}},
 			expected: ResultError{
-				Error:   "requesting user is not a member of the owner group",
-				Address: "resource2",
-				Tags:    []terraform.Tag{{Key: "env", Value: "prod"}, {Key: "team", Value: "devops"}},
+				Error:       "requesting user is not a member of the owner group",
+				Address:     "resource2",
+				Tags:        []terraform.Tag{{Key: "env", Value: "prod"}, {Key: "team", Value: "devops"}},
+				Severity:    SeverityError,
+				RuleID:      ruleRequesterOwner.ID,
+				Remediation: ruleRequesterOwner.Remediation,
+				DocsURL:     ruleRequesterOwner.DocsURL(),
 			},
 		},
 		{
@@ -138,9 +195,13 @@ func TestUnit_NewRequestError(t *testing.T) {
 			address: "resource3",
 			tag:     []terraform.Tag{},
 			expected: ResultError{
-				Error:   "requesting user is not a member of the owner group",
-				Address: "resource3",
-				Tags:    []terraform.Tag{},
+				Error:       "requesting user is not a member of the owner group",
+				Address:     "resource3",
+				Tags:        []terraform.Tag{},
+				Severity:    SeverityError,
+				RuleID:      ruleRequesterOwner.ID,
+				Remediation: ruleRequesterOwner.Remediation,
+				DocsURL:     ruleRequesterOwner.DocsURL(),
 			},
 		},
 	}
@@ -167,9 +228,13 @@ func TestUnit_NewApproveError(t *testing.T) {
 			address: "resource1",
 			tag:     []terraform.Tag{{Key: "env", Value: "prod"}},
 			expected: ResultError{
-				Error:   "approval is required from a member of the owner group",
-				Address: "resource1",
-				Tags:    []terraform.Tag{{Key: "env", Value: "prod"}},
+				Error:       "approval is required from a member of the owner group",
+				Address:     "resource1",
+				Tags:        []terraform.Tag{{Key: "env", Value: "prod"}},
+				Severity:    SeverityError,
+				RuleID:      ruleApprovalOwner.ID,
+				Remediation: ruleApprovalOwner.Remediation,
+				DocsURL:     ruleApprovalOwner.DocsURL(),
 			},
 		},
 		{
@@ -177,9 +242,13 @@ func TestUnit_NewApproveError(t *testing.T) {
 			address: "resource2",
 			tag:     []terraform.Tag{{Key: "env", Value: "prod"}, {Key: "team", Value: "devops"}},
 			expected: ResultError{
-				Error:   "approval is required from a member of the owner group",
-				Address: "resource2",
-				Tags:    []terraform.Tag{{Key: "env", Value: "prod"}, {Key: "team", Value: "devops"}},
+				Error:       "approval is required from a member of the owner group",
+				Address:     "resource2",
+				Tags:        []terraform.Tag{{Key: "env", Value: "prod"}, {Key: "team", Value: "devops"}},
+				Severity:    SeverityError,
+				RuleID:      ruleApprovalOwner.ID,
+				Remediation: ruleApprovalOwner.Remediation,
+				DocsURL:     ruleApprovalOwner.DocsURL(),
 			},
 		},
 		{
@@ -187,9 +256,13 @@ func TestUnit_NewApproveError(t *testing.T) {
 			address: "resource3",
 			tag:     []terraform.Tag{},
 			expected: ResultError{
-				Error:   "approval is required from a member of the owner group",
-				Address: "resource3",
-				Tags:    []terraform.Tag{},
+				Error:       "approval is required from a member of the owner group",
+				Address:     "resource3",
+				Tags:        []terraform.Tag{},
+				Severity:    SeverityError,
+				RuleID:      ruleApprovalOwner.ID,
+				Remediation: ruleApprovalOwner.Remediation,
+				DocsURL:     ruleApprovalOwner.DocsURL(),
 			},
 		},
 	}
@@ -204,6 +277,217 @@ func TestUnit_NewApproveError(t *testing.T) {
 	}
 }
 
+func TestUnit_NewDestructiveChangeError(t *testing.T) {
+	tags := []terraform.Tag{{Key: "env", Value: "prod"}}
+	expected := ResultError{
+		Error: "destructive change: replacing the resource deletes its data, " +
+			"approval is required from a member of the owner group",
+		Address:     "resource1",
+		Tags:        []terraform.Tag{{Key: "env", Value: "prod"}},
+		Severity:    SeverityError,
+		RuleID:      ruleDestructiveChange.ID,
+		Remediation: ruleDestructiveChange.Remediation,
+		DocsURL:     ruleDestructiveChange.DocsURL(),
+	}
+
+	result := newDestructiveChangeError("resource1", &tags)
+	if !assert.ObjectsAreEqual(expected, result) {
+		t.Errorf("expected %v, got %v", expected, result)
+	}
+
+	result = newDestructiveChangeError("resource1", nil)
+	if !assert.ObjectsAreEqual([]terraform.Tag{}, result.Tags) {
+		t.Errorf("expected no tags, got %v", result.Tags)
+	}
+}
+
 func stringPtr(s string) *string {
 	return &s
 }
+
+func TestUnit_WithOwnerGroup(t *testing.T) {
+	t.Run("Names the eligible approvers in the remediation", func(t *testing.T) {
+		ownerGroup := &OwnerGroup{ID: "ug1", Name: "payments", EligibleApprovers: []string{"alice", "bob"}}
+		result := newApproveError("resource1", nil).withOwnerGroup(ownerGroup)
+		assert.Equal(t, ownerGroup, result.OwnerGroup)
+		assert.Equal(t, "Request a review from one of: alice, bob", result.Remediation)
+	})
+
+	t.Run("Keeps the remediation of the rule if there are no eligible approvers", func(t *testing.T) {
+		result := newApproveError("resource1", nil).withOwnerGroup(&OwnerGroup{ID: "ug1", EligibleApprovers: []string{}})
+		assert.Equal(t, ruleApprovalOwner.Remediation, result.Remediation)
+
+		result = newApproveError("resource1", nil).withOwnerGroup(nil)
+		assert.Nil(t, result.OwnerGroup)
+		assert.Equal(t, ruleApprovalOwner.Remediation, result.Remediation)
+	})
+}
+
+func TestUnit_WithoutApprovers(t *testing.T) {
+	ownerGroup := &OwnerGroup{ID: "ug1", Name: "payments", EligibleApprovers: []string{"alice", "bob"}}
+
+	t.Run("Removes the users from the eligible approvers and the remediation", func(t *testing.T) {
+		result := newApproveError("resource1", nil).withOwnerGroup(ownerGroup).withoutApprovers([]string{"alice"})
+		assert.Equal(t, []string{"bob"}, result.OwnerGroup.EligibleApprovers)
+		assert.Equal(t, "Request a review from one of: bob", result.Remediation)
+		assert.Equal(t, []string{"alice", "bob"}, ownerGroup.EligibleApprovers)
+	})
+
+	t.Run("Restores the remediation of the rule if no eligible approvers are left", func(t *testing.T) {
+		result := newApproveError("resource1", nil).withOwnerGroup(ownerGroup).withoutApprovers([]string{"alice", "bob"})
+		assert.Empty(t, result.OwnerGroup.EligibleApprovers)
+		assert.Equal(t, ruleApprovalOwner.Remediation, result.Remediation)
+	})
+}
+
+func TestUnit_SubjectTopicName(t *testing.T) {
+	tests := []struct {
+		subject   string
+		topicName string
+		ok        bool
+	}{
+		{subject: "orders-value", topicName: "orders", ok: true},
+		{subject: "orders-key", topicName: "orders", ok: true},
+		{subject: "orders-key-value", topicName: "orders-key", ok: true},
+		{subject: "-value", ok: false},
+		{subject: "com.example.Order", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.subject, func(t *testing.T) {
+			topicName, ok := subjectTopicName(tt.subject)
+			assert.Equal(t, tt.topicName, topicName)
+			assert.Equal(t, tt.ok, ok)
+		})
+	}
+}
+
+func TestUnit_FindSchemaTopic(t *testing.T) {
+	plan := getTestPlan(t, "testdata/plan_with_schemas.json")
+	schema := func(subject, service string) *terraform.ResourceChangeValues {
+		return &terraform.ResourceChangeValues{
+			Project:     stringPtr("testproject-hpo9"),
+			ServiceName: stringPtr(service),
+			SubjectName: stringPtr(subject),
+		}
+	}
+
+	t.Run("Finds the topic of the subject in the same service", func(t *testing.T) {
+		topic, err := findSchemaTopic(schema("payments-key", "kafka1"), plan)
+		assert.Nil(t, err)
+		assert.Equal(t, "aiven_kafka_topic.payments", topic.Address)
+	})
+
+	t.Run("Does not find topics of another service", func(t *testing.T) {
+		_, err := findSchemaTopic(schema("payments-key", "kafka2"), plan)
+		assert.EqualError(t, err, "topic payments of subject payments-key is not found in the plan or the state")
+	})
+
+	t.Run("Returns error if the subject is not known", func(t *testing.T) {
+		_, err := findSchemaTopic(&terraform.ResourceChangeValues{}, plan)
+		assert.EqualError(t, err, "subject is not known")
+	})
+}
+
+func TestUnit_GrantsTopicAccess(t *testing.T) {
+	tests := []struct {
+		name   string
+		acl    terraform.AccessACL
+		grants bool
+	}{
+		{name: "ACL without resource type", acl: terraform.AccessACL{ResourceName: "orders"}, grants: true},
+		{name: "allowing topic ACL", acl: terraform.AccessACL{ResourceType: "Topic", PermissionType: "ALLOW"}, grants: true},
+		{name: "denying topic ACL", acl: terraform.AccessACL{ResourceType: "Topic", PermissionType: "DENY"}, grants: false},
+		{name: "consumer group ACL", acl: terraform.AccessACL{ResourceType: "Group", PermissionType: "ALLOW"}, grants: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.grants, grantsTopicAccess(tt.acl))
+		})
+	}
+}
+
+func TestUnit_GetAccessResources(t *testing.T) {
+	plan := getTestPlan(t, "testdata/plan_with_access_patterns.json")
+	addresses := func(address string) []string {
+		result := []string{}
+		for _, resourceChange := range plan.ResourceChanges {
+			if resourceChange.Address != address {
+				continue
+			}
+			for _, topic := range getAccessResources(accessGrants(resourceChange.Change.After), plan) {
+				result = append(result, topic.Address)
+			}
+		}
+		return result
+	}
+
+	tests := []struct {
+		address string
+		topics  []string
+	}{
+		{address: "aiven_governance_access.orders", topics: []string{"aiven_kafka_topic.orders"}},
+		{
+			address: "aiven_governance_access.payments",
+			topics:  []string{"aiven_kafka_topic.payments", "data.aiven_kafka_topic.payments_refunds"},
+		},
+		{
+			address: "aiven_governance_access.wildcard",
+			topics: []string{
+				"aiven_kafka_topic.orders", "aiven_kafka_topic.payments", "data.aiven_kafka_topic.payments_refunds",
+			},
+		},
+		{address: "aiven_governance_access.shipments", topics: []string{}},
+		{address: "aiven_governance_access.deny_orders", topics: []string{}},
+		{address: "aiven_governance_access.consumer_group", topics: []string{}},
+		{address: "aiven_governance_access.empty", topics: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.address, func(t *testing.T) {
+			assert.Equal(t, tt.topics, addresses(tt.address))
+		})
+	}
+}
+
+func TestUnit_AccessGrants(t *testing.T) {
+	accessData := func(service string, resourceNames ...string) terraform.AccessData {
+		acls := []terraform.AccessACL{}
+		for _, resourceName := range resourceNames {
+			acls = append(acls, terraform.AccessACL{ResourceName: resourceName, Operation: "Read"})
+		}
+		return terraform.AccessData{Project: "testproject-hpo9", ServiceName: service, Acls: acls}
+	}
+	resourceNames := func(grants []accessGrant) []string {
+		result := []string{}
+		for _, grant := range grants {
+			result = append(result, grant.accessData.ServiceName+"/"+grant.acl.ResourceName)
+		}
+		return result
+	}
+
+	t.Run("Lists the ACLs of every access_data block", func(t *testing.T) {
+		values := &terraform.ResourceChangeValues{AccessData: &[]terraform.AccessData{
+			accessData("kafka1", "orders", "payments"), accessData("kafka2", "orders"),
+		}}
+		assert.Equal(t, []string{"kafka1/orders", "kafka1/payments", "kafka2/orders"}, resourceNames(accessGrants(values)))
+	})
+
+	t.Run("Lists no ACLs without values or access_data blocks", func(t *testing.T) {
+		assert.Empty(t, accessGrants(nil))
+		assert.Empty(t, accessGrants(&terraform.ResourceChangeValues{AccessData: &[]terraform.AccessData{}}))
+	})
+
+	t.Run("Lists the ACLs added by an update", func(t *testing.T) {
+		resourceChange := terraform.ResourceChange{Change: terraform.Change{
+			Before: &terraform.ResourceChangeValues{AccessData: &[]terraform.AccessData{
+				accessData("kafka1", "orders", "refunds"),
+			}},
+			After: &terraform.ResourceChangeValues{AccessData: &[]terraform.AccessData{
+				accessData("kafka1", "orders", "payments"), accessData("kafka2", "orders"),
+			}},
+		}}
+		assert.Equal(t, []string{"kafka1/payments", "kafka2/orders"}, resourceNames(addedAccessGrants(resourceChange)))
+	})
+}
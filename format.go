@@ -0,0 +1,329 @@
+package main
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"aiven/terraform/governance/compliance/checker/internal/junit"
+	"aiven/terraform/governance/compliance/checker/internal/sarif"
+	"aiven/terraform/governance/compliance/checker/internal/terraform"
+)
+
+// FormatContext is what formatters need besides the result to render it
+type FormatContext struct {
+	// PlanPath is the path of the plan file the result is for
+	PlanPath string
+	// Sources finds the declarations of resources, nil if no configuration directory is provided
+	Sources *terraform.Sources
+}
+
+// Formatter renders the result in an output format
+type Formatter func(result Result, context FormatContext) string
+
+var formatters = map[string]Formatter{
+	"json":     func(result Result, _ FormatContext) string { return result.toJSON() },
+	"sarif":    formatSARIF,
+	"markdown": formatMarkdown,
+	"junit":    formatJUnit,
+	"github":   formatGitHub,
+}
+
+func findFormatter(format string) (Formatter, error) {
+	formatter, ok := formatters[format]
+	if !ok {
+		return nil, fmt.Errorf("unknown format %q", format)
+	}
+	return formatter, nil
+}
+
+var sarifLevels = map[Severity]sarif.Level{
+	SeverityError:   sarif.LevelError,
+	SeverityWarning: sarif.LevelWarning,
+	SeverityInfo:    sarif.LevelNote,
+}
+
+// formatSARIF renders the errors and warnings as SARIF results of the registered rules, located at the block
+// declaring the resource when it can be found and at the plan otherwise
+func formatSARIF(result Result, context FormatContext) string {
+	ruleIDs := slices.Sorted(maps.Keys(rules))
+	descriptors := make([]sarif.ReportingDescriptor, 0, len(ruleIDs))
+	for _, id := range ruleIDs {
+		rule := rules[id]
+		descriptors = append(descriptors, sarif.ReportingDescriptor{
+			ID:               rule.ID,
+			ShortDescription: sarif.Message{Text: rule.Description},
+			Help:             sarif.Message{Text: rule.Remediation},
+			HelpURI:          rule.DocsURL(),
+		})
+	}
+
+	results := []sarif.Result{}
+	for _, err := range slices.Concat(result.Errors, result.Warnings) {
+		results = append(results, sarif.Result{
+			RuleID:    err.RuleID,
+			RuleIndex: slices.Index(ruleIDs, err.RuleID),
+			Level:     sarifLevels[err.Severity],
+			Message:   sarif.Message{Text: err.message()},
+			Locations: []sarif.Location{sarifLocation(err.Address, context)},
+		})
+	}
+
+	log := sarif.NewLog(sarif.Run{
+		Tool: sarif.Tool{Driver: sarif.Driver{
+			Name:           "aiven-terraform-governance-compliance-checker",
+			InformationURI: "https://github.com/aiven/aiven-terraform-governance-compliance-checker",
+			Rules:          descriptors,
+		}},
+		Results: results,
+	})
+	encoded, _ := json.Marshal(log)
+	return string(encoded)
+}
+
+func sarifLocation(address string, context FormatContext) sarif.Location {
+	location := sarif.Location{
+		PhysicalLocation: &sarif.PhysicalLocation{
+			ArtifactLocation: sarif.ArtifactLocation{URI: filepath.ToSlash(context.PlanPath)},
+		},
+		LogicalLocations: []sarif.LogicalLocation{{FullyQualifiedName: address, Kind: "resource"}},
+	}
+
+	if source := findSource(address, context); source != nil {
+		location.PhysicalLocation = &sarif.PhysicalLocation{
+			ArtifactLocation: sarif.ArtifactLocation{URI: filepath.ToSlash(source.File)},
+			Region:           &sarif.Region{StartLine: source.Line},
+		}
+	}
+	return location
+}
+
+// findSource returns the location of the block declaring the resource, nil if it can't be found
+func findSource(address string, context FormatContext) *terraform.SourceLocation {
+	if context.Sources == nil {
+		return nil
+	}
+	source, err := context.Sources.Find(address)
+	if err != nil {
+		return nil
+	}
+	return source
+}
+
+// formatGitHub renders the errors and warnings as GitHub Actions workflow commands annotating the block declaring
+// the resource when it can be found and the plan otherwise, errors failing the result are annotated as errors.
+// Diagnostics are warnings without a location.
+func formatGitHub(result Result, context FormatContext) string {
+	annotations := make([]string, 0, len(result.Errors)+len(result.Warnings))
+	if result.Diagnostics != nil {
+		for _, message := range result.Diagnostics.messages() {
+			annotations = append(annotations, "::warning::"+escapeGitHubData(message))
+		}
+	}
+	for _, err := range result.Errors {
+		annotations = append(annotations, githubAnnotation("error", err, context))
+	}
+	for _, err := range result.Warnings {
+		command := "warning"
+		if !err.Severity.AtLeast(SeverityWarning) {
+			command = "notice"
+		}
+		annotations = append(annotations, githubAnnotation(command, err, context))
+	}
+	return strings.Join(annotations, "\n")
+}
+
+func githubAnnotation(command string, err ResultError, context FormatContext) string {
+	properties := []string{"file=" + escapeGitHubProperty(filepath.ToSlash(context.PlanPath))}
+	if source := findSource(err.Address, context); source != nil {
+		properties = []string{
+			"file=" + escapeGitHubProperty(filepath.ToSlash(source.File)),
+			fmt.Sprintf("line=%d", source.Line),
+		}
+	}
+	properties = append(properties, "title="+escapeGitHubProperty(err.RuleID))
+
+	return fmt.Sprintf("::%s %s::%s", command, strings.Join(properties, ","), escapeGitHubData(err.message()))
+}
+
+// message describes the error with its remediation, after the address of the resource if it has one
+func (err ResultError) message() string {
+	if err.Address == "" {
+		return fmt.Sprintf("%s. %s", err.Error, err.Remediation)
+	}
+	return fmt.Sprintf("%s: %s. %s", err.Address, err.Error, err.Remediation)
+}
+
+// escapeGitHubData escapes the message of a workflow command so that it stays on a single line
+func escapeGitHubData(value string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(value)
+}
+
+// escapeGitHubProperty escapes the value of a workflow command property, which also can't contain ':' or ','
+func escapeGitHubProperty(value string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(value)
+}
+
+// formatMarkdown renders the result as a summary and a table with a row per resource, followed by the errors
+// and warnings of each resource
+func formatMarkdown(result Result, _ FormatContext) string {
+	all := slices.Concat(result.Errors, result.Warnings)
+	byAddress := map[string][]ResultError{}
+	var changeErrors []ResultError
+	for _, err := range all {
+		if err.Address == "" {
+			changeErrors = append(changeErrors, err)
+			continue
+		}
+		byAddress[err.Address] = append(byAddress[err.Address], err)
+	}
+	addresses := slices.Sorted(maps.Keys(byAddress))
+
+	var builder strings.Builder
+	status := "✅"
+	if !result.Ok {
+		status = "❌"
+	}
+	fmt.Fprintf(&builder, "### Compliance report: %s\n\n", status)
+	fmt.Fprintf(&builder, "%s, %s in %s\n",
+		pluralize(len(result.Errors), "error"), pluralize(len(result.Warnings), "warning"),
+		pluralize(len(addresses), "resource"),
+	)
+	if result.Directory != nil {
+		fmt.Fprintf(&builder, "\nMemberships include the directory snapshot of %s, %s old\n",
+			result.Directory.GeneratedAt.Format(time.RFC3339), time.Duration(result.Directory.AgeSeconds)*time.Second,
+		)
+	}
+	if result.Diagnostics != nil {
+		builder.WriteString("\n#### Diagnostics\n")
+		for _, message := range result.Diagnostics.messages() {
+			fmt.Fprintf(&builder, "- %s\n", message)
+		}
+	}
+	if len(changeErrors) > 0 {
+		builder.WriteString("\n#### Change\n")
+		for _, err := range changeErrors {
+			fmt.Fprintf(&builder, "- **%s** [%s](%s) %s. %s\n",
+				err.Severity, err.RuleID, err.DocsURL, err.Error, err.Remediation,
+			)
+		}
+	}
+	if len(addresses) == 0 {
+		return builder.String()
+	}
+
+	builder.WriteString("\n| Resource | Action | Owner group | Tags | Failed rules | Eligible approvers |\n")
+	builder.WriteString("|----------|--------|-------------|------|--------------|--------------------|\n")
+	for _, address := range addresses {
+		errs := byAddress[address]
+		var ownerGroup string
+		var approvers, ruleIDs []string
+		for _, err := range errs {
+			if err.OwnerGroup != nil {
+				ownerGroup = markdownOwnerGroup(err.OwnerGroup)
+				approvers = append(approvers, err.OwnerGroup.EligibleApprovers...)
+			}
+			rule := fmt.Sprintf("[%s](%s)", err.RuleID, err.DocsURL)
+			if !err.Severity.AtLeast(SeverityError) {
+				rule = "⚠️ " + rule
+			}
+			ruleIDs = append(ruleIDs, rule)
+		}
+		slices.Sort(approvers)
+
+		fmt.Fprintf(&builder, "| `%s` | %s | %s | %s | %s | %s |\n",
+			address, errs[0].Action, escapeMarkdown(ownerGroup), escapeMarkdown(markdownTags(errs[0].Tags)),
+			strings.Join(slices.Compact(ruleIDs), "<br>"), strings.Join(slices.Compact(approvers), ", "),
+		)
+	}
+
+	for _, address := range addresses {
+		fmt.Fprintf(&builder, "\n#### `%s`\n", address)
+		for _, err := range byAddress[address] {
+			fmt.Fprintf(&builder, "- **%s** %s. %s\n", err.Severity, err.Error, err.Remediation)
+		}
+	}
+	return builder.String()
+}
+
+func markdownOwnerGroup(ownerGroup *OwnerGroup) string {
+	switch {
+	case ownerGroup.Name != "" && ownerGroup.ID != "":
+		return fmt.Sprintf("%s (%s)", ownerGroup.Name, ownerGroup.ID)
+	case ownerGroup.Name != "":
+		return ownerGroup.Name
+	}
+	return ownerGroup.ID
+}
+
+func markdownTags(tags []terraform.Tag) string {
+	formatted := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		formatted = append(formatted, fmt.Sprintf("%s=%s", tag.Key, tag.Value))
+	}
+	return strings.Join(formatted, ", ")
+}
+
+func escapeMarkdown(value string) string {
+	return strings.ReplaceAll(value, "|", "\\|")
+}
+
+func pluralize(count int, noun string) string {
+	if count == 1 {
+		return fmt.Sprintf("%d %s", count, noun)
+	}
+	return fmt.Sprintf("%d %ss", count, noun)
+}
+
+// formatJUnit renders a test suite per resource change with a test case per check, resources no checks run for
+// have a single skipped test case
+func formatJUnit(result Result, _ FormatContext) string {
+	suites := []junit.TestSuite{}
+	suiteIndex := map[string]int{}
+	for _, evaluation := range result.Evaluations {
+		i, ok := suiteIndex[evaluation.Address]
+		if !ok {
+			i = len(suites)
+			suiteIndex[evaluation.Address] = i
+			suites = append(suites, junit.TestSuite{Name: evaluation.Address})
+		}
+		suites[i].TestCases = append(suites[i].TestCases, junitTestCase(evaluation))
+	}
+
+	encoded, _ := xml.MarshalIndent(junit.NewTestSuites("aiven-terraform-governance-compliance", suites...), "", "  ")
+	return xml.Header + string(encoded)
+}
+
+func junitTestCase(evaluation CheckEvaluation) junit.TestCase {
+	testCase := junit.TestCase{Name: evaluation.Check, ClassName: evaluation.Address}
+
+	messages := make([]string, 0, len(evaluation.Errors))
+	ruleIDs := make([]string, 0, len(evaluation.Errors))
+	details := make([]string, 0, len(evaluation.Errors))
+	for _, err := range evaluation.Errors {
+		messages = append(messages, err.Error)
+		ruleIDs = append(ruleIDs, err.RuleID)
+		details = append(details, fmt.Sprintf("%s %s: %s. %s", err.Severity, err.RuleID, err.Error, err.Remediation))
+	}
+
+	switch evaluation.Outcome {
+	case OutcomeSkipped:
+		testCase.Name = "no checks"
+		testCase.Skipped = &junit.Skipped{Message: fmt.Sprintf("no checks run for %s", evaluation.Type)}
+	case OutcomeFailed:
+		testCase.Failure = &junit.Failure{
+			Message: strings.Join(messages, "; "),
+			Type:    strings.Join(slices.Compact(ruleIDs), ","),
+			Text:    strings.Join(details, "\n"),
+		}
+	case OutcomeWarned:
+		testCase.SystemOut = strings.Join(details, "\n")
+	case OutcomePassed:
+	}
+	return testCase
+}
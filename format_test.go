@@ -0,0 +1,227 @@
+package main
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"aiven/terraform/governance/compliance/checker/internal/sarif"
+	"aiven/terraform/governance/compliance/checker/internal/terraform"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatSARIF(t *testing.T) {
+	plan := getTestPlan(t, "testdata/plan_with_modules.json")
+	context := FormatContext{
+		PlanPath: "testdata/plan_with_modules.json",
+		Sources:  terraform.NewSources("testdata/config/plan_with_modules", plan),
+	}
+	result := Result{
+		Ok:     false,
+		Errors: []ResultError{newApproveError("module.payments.aiven_kafka_topic.orders", nil)},
+		Warnings: []ResultError{
+			newMissingTagsError("aiven_kafka_topic.unknown", nil, []string{"team"}),
+		},
+	}
+
+	var log sarif.Log
+	assert.Nil(t, json.Unmarshal([]byte(formatSARIF(result, context)), &log))
+	assert.Equal(t, "2.1.0", log.Version)
+	assert.Len(t, log.Runs, 1)
+
+	run := log.Runs[0]
+	assert.Len(t, run.Tool.Driver.Rules, len(rules))
+	assert.Len(t, run.Results, 2)
+
+	t.Run("Describes the rules of the registry", func(t *testing.T) {
+		rule := run.Tool.Driver.Rules[run.Results[0].RuleIndex]
+		assert.Equal(t, ruleApprovalOwner.ID, rule.ID)
+		assert.Equal(t, ruleApprovalOwner.Description, rule.ShortDescription.Text)
+		assert.Equal(t, ruleApprovalOwner.Remediation, rule.Help.Text)
+		assert.Equal(t, ruleApprovalOwner.DocsURL(), rule.HelpURI)
+	})
+
+	t.Run("Locates errors at the resource block", func(t *testing.T) {
+		assert.Equal(t, ruleApprovalOwner.ID, run.Results[0].RuleID)
+		assert.Equal(t, sarif.LevelError, run.Results[0].Level)
+		assert.Equal(t, &sarif.PhysicalLocation{
+			ArtifactLocation: sarif.ArtifactLocation{URI: "testdata/config/plan_with_modules/modules/topic/main.tf"},
+			Region:           &sarif.Region{StartLine: 6},
+		}, run.Results[0].Locations[0].PhysicalLocation)
+		assert.Equal(t,
+			[]sarif.LogicalLocation{{FullyQualifiedName: "module.payments.aiven_kafka_topic.orders", Kind: "resource"}},
+			run.Results[0].Locations[0].LogicalLocations,
+		)
+	})
+
+	t.Run("Locates warnings at the plan if the resource block is not found", func(t *testing.T) {
+		assert.Equal(t, ruleRequiredTags.ID, run.Results[1].RuleID)
+		assert.Equal(t, sarif.LevelWarning, run.Results[1].Level)
+		assert.Equal(t, &sarif.PhysicalLocation{
+			ArtifactLocation: sarif.ArtifactLocation{URI: "testdata/plan_with_modules.json"},
+		}, run.Results[1].Locations[0].PhysicalLocation)
+	})
+}
+
+func TestFormatMarkdown(t *testing.T) {
+	t.Run("Groups the errors and warnings per resource", func(t *testing.T) {
+		ownerGroup := &OwnerGroup{ID: "ug1", Name: "payments", EligibleApprovers: []string{"alice", "bob"}}
+		approveError := newApproveError("aiven_kafka_topic.foo", &[]terraform.Tag{{Key: "team", Value: "a|b"}}).
+			withOwnerGroup(ownerGroup)
+		approveError.Action = terraform.UpdateChange
+		tagsError := newMissingTagsError("aiven_kafka_topic.foo", &[]terraform.Tag{{Key: "team", Value: "a|b"}},
+			[]string{"env"},
+		)
+		tagsError.Action = terraform.UpdateChange
+
+		result := Result{Ok: false, Errors: []ResultError{approveError}, Warnings: []ResultError{tagsError}}
+
+		//nolint: lll
+		expected := "### Compliance report: ❌\n\n" +
+			"1 error, 1 warning in 1 resource\n\n" +
+			"| Resource | Action | Owner group | Tags | Failed rules | Eligible approvers |\n" +
+			"|----------|--------|-------------|------|--------------|--------------------|\n" +
+			"| `aiven_kafka_topic.foo` | update | payments (ug1) | team=a\\|b | [AKG002-approval-owner](" + ruleApprovalOwner.DocsURL() + ")<br>⚠️ [AKG006-required-tags](" + ruleRequiredTags.DocsURL() + ") | alice, bob |\n" +
+			"\n#### `aiven_kafka_topic.foo`\n" +
+			"- **error** approval is required from a member of the owner group. Request a review from one of: alice, bob\n" +
+			"- **warning** required tags are missing: env. Add the missing tags to the resource\n"
+		assert.Equal(t, expected, formatMarkdown(result, FormatContext{}))
+	})
+
+	t.Run("Only reports the summary if there are no errors", func(t *testing.T) {
+		result := Result{Ok: true, Errors: []ResultError{}, Warnings: []ResultError{}}
+		expected := "### Compliance report: ✅\n\n0 errors, 0 warnings in 0 resources\n"
+		assert.Equal(t, expected, formatMarkdown(result, FormatContext{}))
+	})
+	t.Run("Tells the age of the directory snapshot", func(t *testing.T) {
+		result := Result{
+			Ok:        true,
+			Directory: &DirectoryReport{GeneratedAt: time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC), AgeSeconds: 5400},
+		}
+		expected := "### Compliance report: ✅\n\n0 errors, 0 warnings in 0 resources\n\n" +
+			"Memberships include the directory snapshot of 2026-10-01T12:00:00Z, 1h30m0s old\n"
+		assert.Equal(t, expected, formatMarkdown(result, FormatContext{}))
+	})
+
+	t.Run("Lists the diagnostics below the summary", func(t *testing.T) {
+		result := Result{
+			Ok:          true,
+			Diagnostics: &Diagnostics{UnresolvedIdentities: []UnresolvedIdentity{{User: "frank", Role: RoleApprover}}},
+		}
+		expected := "### Compliance report: ✅\n\n0 errors, 0 warnings in 0 resources\n\n" +
+			"#### Diagnostics\n" +
+			"- approver frank has no aiven_external_identity, map it in the state or with an identities file\n"
+		assert.Equal(t, expected, formatMarkdown(result, FormatContext{}))
+	})
+
+	t.Run("Lists the errors of the change apart from the resources", func(t *testing.T) {
+		result := Result{Ok: false, Errors: []ResultError{newRequesterUnresolvedError("frank")}}
+		expected := "### Compliance report: ❌\n\n1 error, 0 warnings in 0 resources\n\n" +
+			"#### Change\n" +
+			"- **error** [AKG012-requester-unresolved](" + ruleRequesterUnresolved.DocsURL() + ") " +
+			"requester \"frank\" has no aiven_external_identity. " + ruleRequesterUnresolved.Remediation + "\n"
+		assert.Equal(t, expected, formatMarkdown(result, FormatContext{}))
+	})
+}
+
+func TestFormatJUnit(t *testing.T) {
+	approveError := newApproveError("aiven_kafka_topic.foo", nil)
+	tagsError := newMissingTagsError("aiven_kafka_topic.foo", nil, []string{"env"})
+	result := Result{Evaluations: []CheckEvaluation{
+		{
+			Address: "aiven_kafka_topic.foo", Type: "aiven_kafka_topic", Check: "change_is_requested_by_owner",
+			Outcome: OutcomePassed,
+		},
+		{
+			Address: "aiven_kafka_topic.foo", Type: "aiven_kafka_topic", Check: "change_is_approved_by_owner",
+			Outcome: OutcomeFailed, Errors: []ResultError{approveError},
+		},
+		{
+			Address: "aiven_kafka_topic.foo", Type: "aiven_kafka_topic", Check: "required_tags",
+			Outcome: OutcomeWarned, Errors: []ResultError{tagsError},
+		},
+		{Address: "aiven_kafka.foo", Type: "aiven_kafka", Outcome: OutcomeSkipped},
+	}}
+
+	//nolint: lll
+	expected := xml.Header + `<testsuites name="aiven-terraform-governance-compliance" tests="4" failures="1" skipped="1">
+  <testsuite name="aiven_kafka_topic.foo" tests="3" failures="1" skipped="0">
+    <testcase name="change_is_requested_by_owner" classname="aiven_kafka_topic.foo"></testcase>
+    <testcase name="change_is_approved_by_owner" classname="aiven_kafka_topic.foo">
+      <failure message="approval is required from a member of the owner group" type="AKG002-approval-owner">error AKG002-approval-owner: approval is required from a member of the owner group. Request a review from a member of the owner group</failure>
+    </testcase>
+    <testcase name="required_tags" classname="aiven_kafka_topic.foo">
+      <system-out>warning AKG006-required-tags: required tags are missing: env. Add the missing tags to the resource</system-out>
+    </testcase>
+  </testsuite>
+  <testsuite name="aiven_kafka.foo" tests="1" failures="0" skipped="1">
+    <testcase name="no checks" classname="aiven_kafka.foo">
+      <skipped message="no checks run for aiven_kafka"></skipped>
+    </testcase>
+  </testsuite>
+</testsuites>`
+	assert.Equal(t, expected, formatJUnit(result, FormatContext{}))
+}
+
+func TestFormatGitHub(t *testing.T) {
+	plan := getTestPlan(t, "testdata/plan_with_modules.json")
+	context := FormatContext{
+		PlanPath: "testdata/plan_with_modules.json",
+		Sources:  terraform.NewSources("testdata/config/plan_with_modules", plan),
+	}
+
+	t.Run("Annotates the resource block and the plan if the block is not found", func(t *testing.T) {
+		result := Result{
+			Ok:     false,
+			Errors: []ResultError{newApproveError("module.payments.aiven_kafka_topic.orders", nil)},
+			Warnings: []ResultError{
+				newMissingTagsError("aiven_kafka_topic.unknown", nil, []string{"team"}),
+				withSeverity(newMissingTagsError("aiven_kafka_topic.unknown", nil, []string{"env"}), SeverityInfo),
+			},
+		}
+
+		//nolint: lll
+		expected := "::error file=testdata/config/plan_with_modules/modules/topic/main.tf,line=6,title=AKG002-approval-owner::module.payments.aiven_kafka_topic.orders: approval is required from a member of the owner group. Request a review from a member of the owner group\n" +
+			"::warning file=testdata/plan_with_modules.json,title=AKG006-required-tags::aiven_kafka_topic.unknown: required tags are missing: team. Add the missing tags to the resource\n" +
+			"::notice file=testdata/plan_with_modules.json,title=AKG006-required-tags::aiven_kafka_topic.unknown: required tags are missing: env. Add the missing tags to the resource"
+		assert.Equal(t, expected, formatGitHub(result, context))
+	})
+
+	t.Run("Annotates the diagnostics as warnings without a location", func(t *testing.T) {
+		result := Result{
+			Ok:          true,
+			Diagnostics: &Diagnostics{IgnoredSelfApprovals: []string{"alice"}},
+		}
+		assert.Equal(t,
+			"::warning::approval by alice is ignored, requesters can't approve their own change",
+			formatGitHub(result, context),
+		)
+	})
+
+	t.Run("Annotates the errors of the change at the plan without an address", func(t *testing.T) {
+		result := Result{Ok: false, Errors: []ResultError{newRequesterUnresolvedError("frank")}}
+		assert.Equal(t,
+			"::error file=plan.json,title=AKG012-requester-unresolved::requester \"frank\" has no "+
+				"aiven_external_identity. "+ruleRequesterUnresolved.Remediation,
+			formatGitHub(result, FormatContext{PlanPath: "plan.json"}),
+		)
+	})
+
+	t.Run("Annotates warnings failing the result as errors", func(t *testing.T) {
+		result := Result{
+			Ok:     false,
+			Errors: []ResultError{newMissingTagsError("aiven_kafka_topic.unknown", nil, []string{"team"})},
+		}
+		assert.Regexp(t, "^::error ", formatGitHub(result, FormatContext{PlanPath: "plan.json"}))
+	})
+
+	t.Run("Escapes the properties and the message", func(t *testing.T) {
+		err := ResultError{Address: "a", Error: "100%\nsure", RuleID: "AKG:1,2", Remediation: "fix it"}
+		assert.Equal(t,
+			"::error file=C%3A/plan.json,title=AKG%3A1%2C2::a: 100%25%0Asure. fix it",
+			githubAnnotation("error", err, FormatContext{PlanPath: "C:/plan.json"}),
+		)
+	})
+}
@@ -0,0 +1,88 @@
+package directory
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"aiven/terraform/governance/compliance/checker/internal/identity"
+)
+
+// A Snapshot is an offline copy of the users, user groups and memberships of an Aiven organization, for memberships
+// managed in the Aiven console rather than with Terraform. Users and user groups have the shape of the organization
+// users and user groups of the Aiven API, the members of each user group are listed with it:
+//
+//	{
+//	  "generated_at": "2026-10-01T12:00:00Z",
+//	  "users": [
+//	    {"user_id": "u4e3b0f0cccc", "user_info": {"user_email": "charlie@example.com", "real_name": "Charlie"}}
+//	  ],
+//	  "user_groups": [
+//	    {"user_group_id": "ug4e3b20cee48", "user_group_name": "foo", "members": [{"user_id": "u4e3b0f0cccc"}]}
+//	  ]
+//	}
+
+type Snapshot struct {
+	// GeneratedAt is when the snapshot is taken, the report tells its age
+	GeneratedAt time.Time   `json:"generated_at"`
+	Users       []User      `json:"users"`
+	UserGroups  []UserGroup `json:"user_groups"`
+}
+
+type User struct {
+	UserID   string   `json:"user_id"`
+	UserInfo UserInfo `json:"user_info"`
+}
+
+type UserInfo struct {
+	UserEmail string `json:"user_email"`
+	RealName  string `json:"real_name"`
+}
+
+type UserGroup struct {
+	UserGroupID   string   `json:"user_group_id"`
+	UserGroupName string   `json:"user_group_name"`
+	Members       []Member `json:"members"`
+}
+
+type Member struct {
+	UserID string `json:"user_id"`
+}
+
+func Load(path string) (*Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid directory file")
+	}
+
+	var snapshot Snapshot
+	if err = json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("invalid directory file: %w", err)
+	}
+
+	if snapshot.GeneratedAt.IsZero() {
+		return nil, fmt.Errorf("invalid directory file: generated_at is required")
+	}
+	for i, group := range snapshot.UserGroups {
+		if group.UserGroupID == "" {
+			return nil, fmt.Errorf("invalid directory file: user group %d: user_group_id is required", i+1)
+		}
+	}
+	return &snapshot, nil
+}
+
+// Identities maps the email of each user to its user ID, so that requesters and approvers can be identified by
+// their email
+func (snapshot *Snapshot) Identities() []identity.Identity {
+	identities := []identity.Identity{}
+	for _, user := range snapshot.Users {
+		if user.UserID != "" && user.UserInfo.UserEmail != "" {
+			identities = append(identities, identity.Identity{
+				InternalUserID: user.UserID,
+				Aliases:        []string{user.UserInfo.UserEmail},
+			})
+		}
+	}
+	return identities
+}
@@ -0,0 +1,118 @@
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+)
+
+// The requester and approvers of a change can be read from the files GitHub Actions provides: the payload of the
+// pull_request or pull_request_review event ($GITHUB_EVENT_PATH) and the reviews of the pull request, as listed by
+// GET /repos/{owner}/{repo}/pulls/{pull_number}/reviews.
+
+type ReviewState string
+
+const (
+	ReviewApproved         ReviewState = "APPROVED"
+	ReviewChangesRequested ReviewState = "CHANGES_REQUESTED"
+	ReviewCommented        ReviewState = "COMMENTED"
+	ReviewDismissed        ReviewState = "DISMISSED"
+	ReviewPending          ReviewState = "PENDING"
+)
+
+type User struct {
+	Login string `json:"login"`
+}
+
+type Event struct {
+	PullRequest *PullRequest `json:"pull_request"`
+}
+
+type PullRequest struct {
+	User User `json:"user"`
+	Head Head `json:"head"`
+}
+
+type Head struct {
+	SHA string `json:"sha"`
+}
+
+type Review struct {
+	User        User        `json:"user"`
+	State       ReviewState `json:"state"`
+	CommitID    string      `json:"commit_id"`
+	SubmittedAt time.Time   `json:"submitted_at"`
+}
+
+func NewEvent(path string) (*Event, error) {
+	var event Event
+	if err := readJSON(path, &event); err != nil {
+		return nil, fmt.Errorf("invalid GitHub event file")
+	}
+	if event.PullRequest == nil {
+		return nil, fmt.Errorf("invalid GitHub event file: not a pull request event")
+	}
+	return &event, nil
+}
+
+func NewReviews(path string) ([]Review, error) {
+	var reviews []Review
+	if err := readJSON(path, &reviews); err != nil {
+		return nil, fmt.Errorf("invalid GitHub reviews file")
+	}
+	return reviews, nil
+}
+
+func readJSON(path string, value any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, value)
+}
+
+// LatestReviews returns the latest review of each user that decides whether they approve the pull request,
+// comments and pending reviews don't change an earlier approval or request for changes. Reviews are ordered by the
+// time they are submitted at, reviews without that time keep the order of the list.
+func LatestReviews(reviews []Review) map[string]Review {
+	submitted := slices.Clone(reviews)
+	slices.SortStableFunc(submitted, func(a, b Review) int {
+		return a.SubmittedAt.Compare(b.SubmittedAt)
+	})
+
+	latest := map[string]Review{}
+	for _, review := range submitted {
+		if review.State == ReviewCommented || review.State == ReviewPending {
+			continue
+		}
+		latest[review.User.Login] = review
+	}
+	return latest
+}
+
+// Approvers returns the sorted logins of the users whose latest review approves the pull request, a dismissed
+// review or a later request for changes revokes the approval
+func Approvers(reviews []Review) []string {
+	approvers := []string{}
+	for _, review := range LatestReviews(reviews) {
+		if review.State == ReviewApproved {
+			approvers = append(approvers, review.User.Login)
+		}
+	}
+	slices.Sort(approvers)
+	return approvers
+}
+
+// StaleApprovals returns the commits the approvals of users are given for, keyed by user, if the latest review of
+// the user approves an earlier commit than the head of the pull request
+func StaleApprovals(reviews []Review, headSHA string) map[string]string {
+	stale := map[string]string{}
+	for login, review := range LatestReviews(reviews) {
+		if review.State == ReviewApproved && review.CommitID != headSHA {
+			stale[login] = review.CommitID
+		}
+	}
+	return stale
+}
@@ -0,0 +1,97 @@
+package identity
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// An identities file maps the logins and email aliases users are known by in GitHub or GitLab to the internal
+// user IDs of their Aiven organization users, for users whose aiven_external_identity is not in the Terraform
+// state yet. Files are written in YAML:
+//
+//	identities:
+//	  - internal_user_id: u4e3706199a0
+//	    aliases: [alice, alice@example.com]
+//
+// or, if the file has a .csv extension, in CSV with a header row:
+//
+//	alias,internal_user_id
+//	alice,u4e3706199a0
+//	alice@example.com,u4e3706199a0
+
+type Identity struct {
+	InternalUserID string   `yaml:"internal_user_id"`
+	Aliases        []string `yaml:"aliases"`
+}
+
+type File struct {
+	Identities []Identity `yaml:"identities"`
+}
+
+var csvHeader = []string{"alias", "internal_user_id"}
+
+func Load(path string) ([]Identity, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid identities file")
+	}
+
+	var identities []Identity
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		identities, err = parseCSV(data)
+	} else {
+		identities, err = parseYAML(data)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid identities file: %w", err)
+	}
+
+	for i, identity := range identities {
+		if identity.InternalUserID == "" {
+			return nil, fmt.Errorf("invalid identities file: identity %d: internal_user_id is required", i+1)
+		}
+		if len(identity.Aliases) == 0 || slices.Contains(identity.Aliases, "") {
+			return nil, fmt.Errorf("invalid identities file: identity %d: aliases can't be empty", i+1)
+		}
+	}
+	return identities, nil
+}
+
+func parseYAML(data []byte) ([]Identity, error) {
+	var file File
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	return file.Identities, nil
+}
+
+// parseCSV returns an identity per row
+func parseCSV(data []byte) ([]Identity, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = len(csvHeader)
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 || !slices.Equal(records[0], csvHeader) {
+		return nil, fmt.Errorf("the header must be %s", strings.Join(csvHeader, ","))
+	}
+
+	identities := make([]Identity, 0, len(records)-1)
+	for _, record := range records[1:] {
+		identities = append(identities, Identity{InternalUserID: record[1], Aliases: []string{record[0]}})
+	}
+	return identities, nil
+}
@@ -1,35 +1,150 @@
 package input
 
 import (
+	"errors"
 	"flag"
 	"fmt"
+	"slices"
 	"strings"
+
+	"aiven/terraform/governance/compliance/checker/internal/github"
 )
 
 type Input struct {
-	Plan      string
-	Requester string
-	Approvers []string
+	Plan       string
+	Requester  string
+	Approvers  []string
+	Policy     string
+	FailOn     string
+	Format     string
+	ConfigDir  string
+	ReportAll  bool
+	ExitCode   bool
+	Identities string
+	// Directory is the path to a snapshot of the organization users, user groups and memberships
+	Directory string
+	// Strict fails if the requester has no external identity
+	Strict bool
+	// HeadSHA is the latest commit of the change, from -head-sha or the GitHub event
+	HeadSHA string
+	// StaleApprovals are the commits approvals for an earlier commit than the head are given for, keyed by approver,
+	// only set if stale approvals are rejected
+	StaleApprovals map[string]string
 }
 
+// NewInput parses the CLI args. The input is returned with errors as far as it is parsed, so that the caller knows
+// how to report them, flag.ErrHelp is returned as is when the usage is requested.
 func NewInput(args []string) (*Input, error) {
-	flags := flag.NewFlagSet("checker", flag.ExitOnError)
+	flags := flag.NewFlagSet("checker", flag.ContinueOnError)
 
 	plan := flags.String("plan", "", "path to a file with terraform plan output in json format")
 	requester := flags.String("requester", "", "user identified as the requester of the change")
 	approvers := flags.String("approvers", "", "comma separated list of users identified as the approvers of the change")
+	policy := flags.String("policy", "", "path to a YAML or JSON file with the policy of checks to run per resource type")
+	failOn := flags.String("fail-on", "error", "lowest severity that fails the result: error, warning or info")
+	format := flags.String("format", "json", "output format of the result: json, github, sarif, markdown or junit")
+	configDir := flags.String("config-dir", "", "terraform configuration directory to locate resources in .tf files")
+	reportAll := flags.Bool("report-all", false, "report every evaluated resource with its checks, outcomes and approvals")
+	githubEvent := flags.String("github-event", "",
+		"path to the pull request event payload of GitHub Actions, the requester is its author unless -requester is set",
+	)
+	githubReviews := flags.String("github-reviews", "",
+		"path to the JSON list of reviews of the pull request, the approvers are its users whose latest review approves "+
+			"unless -approvers is set",
+	)
+	identities := flags.String("identities", "",
+		"path to a YAML or CSV file mapping logins and email aliases to internal user IDs, besides the identities in state",
+	)
+	directory := flags.String("directory", "",
+		"path to a JSON snapshot of the organization users, user groups and memberships, consulted besides the state",
+	)
+	strict := flags.Bool("strict", false, "fail if the requester has no aiven_external_identity")
+	headSHA := flags.String("head-sha", "", "latest commit of the change, defaults to the head of the GitHub event")
+	rejectStaleApprovals := flags.Bool("reject-stale-approvals", false,
+		"only count the approvals of -github-reviews given for the latest commit of the change",
+	)
+	exitCode := flags.Bool("exit-code", false,
+		"exit with 2 if the change is not compliant, 3 on warnings only, 4 on invalid input and 5 on internal errors",
+	)
+
+	parseErr := flags.Parse(args)
+
+	input := &Input{
+		Plan:       *plan,
+		Requester:  *requester,
+		Approvers:  strings.Split(*approvers, ","),
+		Policy:     *policy,
+		FailOn:     *failOn,
+		Format:     *format,
+		ConfigDir:  *configDir,
+		ReportAll:  *reportAll,
+		ExitCode:   *exitCode,
+		HeadSHA:    *headSHA,
+		Identities: *identities,
+		Directory:  *directory,
+		Strict:     *strict,
+	}
+
+	if errors.Is(parseErr, flag.ErrHelp) {
+		return input, parseErr
+	}
+	if parseErr != nil {
+		return input, fmt.Errorf("invalid arguments")
+	}
+
+	if input.Plan == "" {
+		return input, fmt.Errorf("plan is a required argument")
+	}
+
+	if *githubEvent != "" {
+		if err := input.readGitHubEvent(*githubEvent); err != nil {
+			return input, err
+		}
+	}
+
+	if *githubReviews != "" && *approvers == "" {
+		if err := input.readGitHubReviews(*githubReviews, *rejectStaleApprovals); err != nil {
+			return input, err
+		}
+	}
+
+	return input, nil
+}
 
-	if err := flags.Parse(args); err != nil {
-		return nil, fmt.Errorf("invalid arguments")
+// readGitHubEvent takes the requester and head SHA from the pull request of the event, unless they are set
+func (input *Input) readGitHubEvent(path string) error {
+	event, err := github.NewEvent(path)
+	if err != nil {
+		return err
 	}
+	if input.Requester == "" {
+		input.Requester = event.PullRequest.User.Login
+	}
+	if input.HeadSHA == "" {
+		input.HeadSHA = event.PullRequest.Head.SHA
+	}
+	return nil
+}
 
-	if *plan == "" {
-		return nil, fmt.Errorf("plan is a required argument")
+// readGitHubReviews takes the approvers from the reviews, approvals for an earlier commit than the head are stale
+// and don't count if they are rejected
+func (input *Input) readGitHubReviews(path string, rejectStaleApprovals bool) error {
+	reviews, err := github.NewReviews(path)
+	if err != nil {
+		return err
 	}
+	input.Approvers = github.Approvers(reviews)
 
-	return &Input{
-		Plan:      *plan,
-		Requester: *requester,
-		Approvers: strings.Split(*approvers, ","),
-	}, nil
+	if !rejectStaleApprovals {
+		return nil
+	}
+	if input.HeadSHA == "" {
+		return fmt.Errorf("head SHA is required to reject stale approvals")
+	}
+	input.StaleApprovals = github.StaleApprovals(reviews, input.HeadSHA)
+	input.Approvers = slices.DeleteFunc(input.Approvers, func(approver string) bool {
+		_, stale := input.StaleApprovals[approver]
+		return stale
+	})
+	return nil
 }
@@ -0,0 +1,125 @@
+package policy
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+)
+
+// A Policy maps resource types, optionally narrowed down to projects and services, to the checks that
+// run for them. Policies are written in YAML or JSON (which is valid YAML):
+//
+//	rules:
+//	  - resource_type: aiven_kafka_topic
+//	    project: sandbox-*
+//	    checks:
+//	      - name: change_is_requested_by_owner
+//	  - resource_type: aiven_kafka_topic
+//	    checks:
+//	      - name: change_is_requested_by_owner
+//	      - name: change_is_approved_by_owner
+//	      - name: required_tags
+//	        severity: warning
+//	        params:
+//	          keys: team
+//
+// Rules are evaluated in order and the first rule matching a resource decides its checks.
+
+type Policy struct {
+	Rules []Rule `yaml:"rules"`
+}
+
+type Rule struct {
+	ResourceType string `yaml:"resource_type"`
+	// Project and Service are shell patterns (path.Match) the project and service name of the resource must match,
+	// an empty pattern matches any value
+	Project string  `yaml:"project"`
+	Service string  `yaml:"service"`
+	Checks  []Check `yaml:"checks"`
+}
+
+type Check struct {
+	Name string `yaml:"name"`
+	// Severity overrides the default severity of the errors of the check: error, warning or info
+	Severity string `yaml:"severity"`
+	Params   Params `yaml:"params"`
+}
+
+type Params map[string]string
+
+// Definitions lists the checks a policy may refer to, with the names of the parameters each of them accepts
+type Definitions map[string][]string
+
+func Load(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid policy file")
+	}
+
+	var policy Policy
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err = decoder.Decode(&policy); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("invalid policy file: %w", err)
+	}
+
+	return &policy, nil
+}
+
+// Validate checks that every rule has a resource type and valid selectors,
+// and that it only refers to known checks and parameters
+func (policy *Policy) Validate(definitions Definitions) error {
+	for i, rule := range policy.Rules {
+		if rule.ResourceType == "" {
+			return fmt.Errorf("invalid policy: rule %d: resource_type is required", i+1)
+		}
+		for _, pattern := range []string{rule.Project, rule.Service} {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("invalid policy: rule %d: invalid selector %q", i+1, pattern)
+			}
+		}
+
+		seen := map[string]bool{}
+		for _, check := range rule.Checks {
+			params, ok := definitions[check.Name]
+			if !ok {
+				return fmt.Errorf("invalid policy: rule %d: unknown check %q", i+1, check.Name)
+			}
+			if seen[check.Name] {
+				return fmt.Errorf("invalid policy: rule %d: check %q is listed more than once", i+1, check.Name)
+			}
+			seen[check.Name] = true
+
+			for param := range check.Params {
+				if !slices.Contains(params, param) {
+					return fmt.Errorf("invalid policy: rule %d: check %q does not accept parameter %q", i+1, check.Name, param)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Match returns the first rule matching the resource type, project and service, nil if no rule matches
+func (policy *Policy) Match(resourceType, project, service string) *Rule {
+	for i, rule := range policy.Rules {
+		if rule.ResourceType == resourceType && matches(rule.Project, project) && matches(rule.Service, service) {
+			return &policy.Rules[i]
+		}
+	}
+	return nil
+}
+
+func matches(pattern, value string) bool {
+	if pattern == "" {
+		return true
+	}
+	matched, err := path.Match(pattern, value)
+	return err == nil && matched
+}
@@ -0,0 +1,242 @@
+package terraform
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// Resource addresses identify resource instances across the plan, the prior state and the configuration:
+// https://developer.hashicorp.com/terraform/cli/state/resource-addressing
+// Resource changes and prior state resources carry the instance keys of the module calls and the resource,
+// e.g. module.team["billing"].aiven_kafka_topic.t["orders"], while the configuration declares the resource
+// once as aiven_kafka_topic.t in module.team.
+
+type ResourceMode string
+
+const (
+	ManagedResourceMode ResourceMode = "managed"
+	DataResourceMode    ResourceMode = "data"
+)
+
+var ErrInvalidAddress = errors.New("resource address is not valid")
+
+// Address is a parsed resource instance address
+type Address struct {
+	// Module is the path of module instances the resource belongs to, empty for the root module
+	Module []ModuleInstance
+	Mode   ResourceMode
+	Type   ResourceType
+	Name   string
+	// Key is the count index (float64) or for_each key (string) of the instance, nil if the resource is not repeated
+	Key any
+}
+
+type ModuleInstance struct {
+	Name string
+	Key  any
+}
+
+// ParseAddress parses an absolute resource instance address
+func ParseAddress(address string) (Address, error) {
+	steps, err := parseTraversal(address)
+	if err != nil {
+		return Address{}, ErrInvalidAddress
+	}
+
+	parsed := Address{Mode: ManagedResourceMode}
+	for len(steps) >= 2 && steps[0].name == "module" && !steps[0].hasKey() {
+		parsed.Module = append(parsed.Module, ModuleInstance{Name: steps[1].name, Key: steps[1].key})
+		steps = steps[2:]
+	}
+
+	if len(steps) > 0 && steps[0].name == "data" && !steps[0].hasKey() {
+		parsed.Mode = DataResourceMode
+		steps = steps[1:]
+	}
+
+	if len(steps) != 2 || steps[0].hasKey() {
+		return Address{}, ErrInvalidAddress
+	}
+	parsed.Type = ResourceType(steps[0].name)
+	parsed.Name = steps[1].name
+	parsed.Key = steps[1].key
+	return parsed, nil
+}
+
+// ModuleAddress returns the address of the module instance, e.g. module.team["billing"]
+func (address Address) ModuleAddress() string {
+	var builder strings.Builder
+	for i, module := range address.Module {
+		if i > 0 {
+			builder.WriteString(".")
+		}
+		builder.WriteString(modulePrefix + module.Name + formatKey(module.Key))
+	}
+	return builder.String()
+}
+
+// ConfigurationModule returns the address of the module without instance keys, as used by the configuration
+func (address Address) ConfigurationModule() string {
+	names := make([]string, 0, len(address.Module))
+	for _, module := range address.Module {
+		names = append(names, module.Name)
+	}
+	return joinModuleAddress("", names...)
+}
+
+// LocalAddress returns the address of the resource instance relative to its module
+func (address Address) LocalAddress() string {
+	local := string(address.Type) + "." + address.Name + formatKey(address.Key)
+	if address.Mode == DataResourceMode {
+		return "data." + local
+	}
+	return local
+}
+
+func (address Address) String() string {
+	return joinAddress(address.ModuleAddress(), address.LocalAddress())
+}
+
+// Matches reports whether the configuration resource declares the resource instance
+func (resource ConfigurationResource) Matches(address Address) bool {
+	return resource.Module == address.ConfigurationModule() &&
+		resource.Mode == address.Mode &&
+		resource.Type == address.Type &&
+		resource.Name == address.Name
+}
+
+// traversal is a parsed reference such as aiven_organization_user_group.g["payments"].group_id
+type traversal []traversalStep
+
+type traversalStep struct {
+	name string
+	// key is the index following the name: a string for for_each keys, a float64 for count indices
+	key any
+}
+
+func (step traversalStep) hasKey() bool {
+	return step.key != nil
+}
+
+func (t traversal) root() string {
+	return t[0].name
+}
+
+// resource returns the part of the traversal that addresses a resource, stripping any attributes
+func (t traversal) resource() (traversal, bool) {
+	length := 2
+	if t.root() == "data" {
+		length = 3
+	}
+	if len(t) < length {
+		return nil, false
+	}
+	// only the last step of the resource address (the resource name) may carry an instance key
+	for _, step := range t[:length-1] {
+		if step.hasKey() {
+			return nil, false
+		}
+	}
+	return t[:length], true
+}
+
+func (t traversal) hasKey() bool {
+	return len(t) > 0 && t[len(t)-1].hasKey()
+}
+
+func (t traversal) String() string {
+	var builder strings.Builder
+	for i, step := range t {
+		if i > 0 {
+			builder.WriteString(".")
+		}
+		builder.WriteString(step.name)
+		if step.hasKey() {
+			builder.WriteString(formatKey(step.key))
+		}
+	}
+	return builder.String()
+}
+
+// parseTraversal splits a reference into its dot separated names and bracketed instance keys
+func parseTraversal(reference string) (traversal, error) {
+	steps := traversal{}
+	rest := reference
+	for rest != "" {
+		end := strings.IndexAny(rest, ".[")
+		if end == -1 {
+			end = len(rest)
+		}
+		step := traversalStep{name: rest[:end]}
+		if step.name == "" {
+			return nil, ErrInvalidReference
+		}
+		rest = rest[end:]
+
+		if strings.HasPrefix(rest, "[") {
+			key, remaining, err := parseKey(rest)
+			if err != nil {
+				return nil, err
+			}
+			step.key = key
+			rest = remaining
+		}
+
+		steps = append(steps, step)
+		if rest == "" {
+			break
+		}
+		if !strings.HasPrefix(rest, ".") || len(rest) == 1 {
+			return nil, ErrInvalidReference
+		}
+		rest = rest[1:]
+	}
+	if len(steps) == 0 {
+		return nil, ErrInvalidReference
+	}
+	return steps, nil
+}
+
+// parseKey parses a bracketed key at the start of the input and returns the remaining input
+func parseKey(input string) (any, string, error) {
+	if strings.HasPrefix(input, `["`) {
+		// find the closing quote, skipping escaped characters
+		for i := 2; i < len(input); i++ {
+			switch input[i] {
+			case '\\':
+				i++
+			case '"':
+				key, err := strconv.Unquote(input[1 : i+1])
+				if err != nil || i+1 >= len(input) || input[i+1] != ']' {
+					return nil, "", ErrInvalidReference
+				}
+				return key, input[i+2:], nil
+			}
+		}
+		return nil, "", ErrInvalidReference
+	}
+
+	end := strings.Index(input, "]")
+	if end == -1 {
+		return nil, "", ErrInvalidReference
+	}
+	index, err := strconv.Atoi(input[1:end])
+	if err != nil {
+		return nil, "", ErrInvalidReference
+	}
+	return float64(index), input[end+1:], nil
+}
+
+// formatKey formats an instance key the way Terraform does in addresses: [0] or ["key"]
+func formatKey(key any) string {
+	switch value := key.(type) {
+	case string:
+		return "[" + strconv.Quote(value) + "]"
+	case float64:
+		return "[" + strconv.FormatFloat(value, 'f', -1, 64) + "]"
+	case int:
+		return "[" + strconv.Itoa(value) + "]"
+	}
+	return ""
+}
@@ -0,0 +1,44 @@
+package terraform
+
+import "slices"
+
+// ChangeKind is the kind of change Terraform plans for a resource instance, derived from the list of actions:
+// https://developer.hashicorp.com/terraform/internals/json-format#change-representation
+type ChangeKind string
+
+const (
+	CreateChange ChangeKind = "create"
+	UpdateChange ChangeKind = "update"
+	DeleteChange ChangeKind = "delete"
+	// ReplaceChange destroys the existing object and creates a new one, either delete then create
+	// or, with create_before_destroy, create then delete
+	ReplaceChange ChangeKind = "replace"
+	NoOpChange    ChangeKind = "no-op"
+	ReadChange    ChangeKind = "read"
+	// ForgetChange removes the object from the state without destroying it
+	ForgetChange ChangeKind = "forget"
+)
+
+// Kind returns the kind of the change. Unrecognised combinations of actions are treated as a replace,
+// which requires checking the resource both before and after the change.
+func (change Change) Kind() ChangeKind {
+	actions := change.Actions
+	switch {
+	case slices.Equal(actions, []ActionType{NoOpAction}):
+		return NoOpChange
+	case slices.Equal(actions, []ActionType{ReadAction}):
+		return ReadChange
+	case slices.Equal(actions, []ActionType{CreateAction}):
+		return CreateChange
+	case slices.Equal(actions, []ActionType{UpdateAction}):
+		return UpdateChange
+	case slices.Equal(actions, []ActionType{DeleteAction}):
+		return DeleteChange
+	case slices.Equal(actions, []ActionType{ForgetAction}):
+		return ForgetChange
+	case slices.Equal(actions, []ActionType{DeleteAction, CreateAction}),
+		slices.Equal(actions, []ActionType{CreateAction, DeleteAction}):
+		return ReplaceChange
+	}
+	return ReplaceChange
+}
@@ -0,0 +1,92 @@
+package terraform
+
+import (
+	"maps"
+	"slices"
+	"strings"
+)
+
+// Terraform represents child modules differently in the state and in the configuration:
+// prior state resources carry absolute addresses (module.payments.aiven_kafka_topic.orders)
+// and are nested under child_modules, while configuration resources are nested under module_calls
+// and both their addresses and the references in their expressions are relative to the module.
+
+const modulePrefix = "module."
+
+// PriorStateResources returns the resources of the root module and all of its child modules
+func (plan *Plan) PriorStateResources() []PriorStateResource {
+	return plan.PriorState.Values.RootModule.allResources()
+}
+
+func (module PriorStateModule) allResources() []PriorStateResource {
+	resources := append([]PriorStateResource{}, module.Resources...)
+	for _, child := range module.ChildModules {
+		resources = append(resources, child.allResources()...)
+	}
+	return resources
+}
+
+// ConfigurationResources returns the resources declared in the root module and all of the called modules.
+// Each resource has its Module set to the address of the module it is declared in.
+func (plan *Plan) ConfigurationResources() []ConfigurationResource {
+	return plan.Configuration.RootModule.allResources("")
+}
+
+func (module ConfigurationModule) allResources(moduleAddress string) []ConfigurationResource {
+	resources := make([]ConfigurationResource, 0, len(module.Resources))
+	for _, resource := range module.Resources {
+		resource.Module = moduleAddress
+		resources = append(resources, resource)
+	}
+	for _, name := range slices.Sorted(maps.Keys(module.ModuleCalls)) {
+		call := module.ModuleCalls[name]
+		resources = append(resources, call.Module.allResources(joinModuleAddress(moduleAddress, name))...)
+	}
+	return resources
+}
+
+// AbsoluteAddress returns the address of the resource including the module path,
+// e.g. module.payments.aiven_kafka_topic.orders
+func (resource ConfigurationResource) AbsoluteAddress() string {
+	return joinAddress(resource.Module, resource.Address)
+}
+
+// findModule returns the configuration of the module with the given address
+func (config Configuration) findModule(moduleAddress string) (*ConfigurationModule, bool) {
+	module := &config.RootModule
+	for _, name := range splitModuleAddress(moduleAddress) {
+		call, ok := module.ModuleCalls[name]
+		if !ok {
+			return nil, false
+		}
+		module = &call.Module
+	}
+	return module, true
+}
+
+func joinModuleAddress(moduleAddress string, names ...string) string {
+	for _, name := range names {
+		moduleAddress = joinAddress(moduleAddress, modulePrefix+name)
+	}
+	return moduleAddress
+}
+
+func splitModuleAddress(moduleAddress string) []string {
+	names := []string{}
+	if moduleAddress == "" {
+		return names
+	}
+	// module addresses alternate between the "module" keyword and the module name
+	parts := strings.Split(moduleAddress, ".")
+	for i := 1; i < len(parts); i += 2 {
+		names = append(names, parts[i])
+	}
+	return names
+}
+
+func joinAddress(moduleAddress, address string) string {
+	if moduleAddress == "" {
+		return address
+	}
+	return moduleAddress + "." + address
+}
@@ -0,0 +1,240 @@
+package terraform
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// Expressions in the plan configuration are not evaluated, Terraform only lists the references they contain:
+// https://developer.hashicorp.com/terraform/internals/json-format#expression-representation
+// For `aiven_organization_user_group.g["payments"].group_id` the references are
+// ["aiven_organization_user_group.g[\"payments\"].group_id", "aiven_organization_user_group.g[\"payments\"]",
+// "aiven_organization_user_group.g"], so the resolver has to pick the most specific resource out of the list.
+
+type ReferenceKind string
+
+const (
+	// ResourceReference points at a managed resource or a data source
+	ResourceReference ReferenceKind = "resource"
+	// ConstantReference is a literal value, e.g. an owner group ID written directly into the configuration
+	ConstantReference ReferenceKind = "constant"
+)
+
+// Reference is the result of resolving an expression
+type Reference struct {
+	Kind ReferenceKind
+	// Address is the absolute address of the referenced resource, including the instance key when known
+	Address string
+	// Value is the literal value of a constant reference
+	Value string
+}
+
+var (
+	ErrEmptyExpression      = errors.New("expression is not set")
+	ErrNoResourceReference  = errors.New("expression does not reference a resource")
+	ErrUnsupportedReference = errors.New("reference can not be resolved from the plan")
+	ErrUnknownVariable      = errors.New("variable is not declared")
+	ErrUnknownOutput        = errors.New("module output is not declared")
+	ErrUnknownModule        = errors.New("module is not declared")
+	ErrInvalidReference     = errors.New("reference is not valid")
+	ErrUnknownInstance      = errors.New("instance of a repeated resource can not be resolved")
+)
+
+// ReferenceError describes why an expression could not be resolved
+type ReferenceError struct {
+	// Module is the address of the module the expression is declared in
+	Module string
+	// Reference is the reference that failed to resolve, empty if the expression has no references
+	Reference string
+	Err       error
+}
+
+func (err *ReferenceError) Error() string {
+	module := err.Module
+	if module == "" {
+		module = "root module"
+	}
+	if err.Reference == "" {
+		return fmt.Sprintf("%s in %s", err.Err, module)
+	}
+	return fmt.Sprintf("%s: %s in %s", err.Err, err.Reference, module)
+}
+
+func (err *ReferenceError) Unwrap() error {
+	return err.Err
+}
+
+// Scope is the context an expression is evaluated in
+type Scope struct {
+	// Module is the address of the module the expression is declared in, empty for the root module
+	Module string
+	// Key is the count index or for_each key of the resource instance the expression belongs to
+	Key any
+}
+
+// ResolveExpression resolves an expression of the configuration to the resource it references or to its constant
+// value. Input variables are followed into the calling module (or the plan variables for the root module) and
+// module outputs into the called module. Locals are not part of the plan representation and can not be resolved.
+func (plan *Plan) ResolveExpression(scope Scope, expression *Expression) (*Reference, error) {
+	if expression == nil {
+		return nil, &ReferenceError{Module: scope.Module, Err: ErrEmptyExpression}
+	}
+
+	if len(expression.References) == 0 {
+		return newConstantReference(scope, "", expression.ConstantValue)
+	}
+
+	var resource, indexed *traversal
+	var instanceKeyed bool
+	var variables, outputs, unsupported, dynamic []traversal
+
+	for _, reference := range expression.References {
+		parsed, err := parseTraversal(reference)
+		if err != nil {
+			return nil, &ReferenceError{Module: scope.Module, Reference: reference, Err: err}
+		}
+
+		switch parsed.root() {
+		case "var":
+			variables = append(variables, parsed)
+		case "module":
+			outputs = append(outputs, parsed)
+		case "count", "each":
+			// count.index and each.key select the instance of a repeated resource with the same key. The references
+			// of the plan don't tell g[each.key] from g[upper(each.key)] or g[count.index + 1], so the key is
+			// assumed to be used as it is
+			if reference == "count.index" || reference == "each.key" {
+				instanceKeyed = true
+			} else {
+				dynamic = append(dynamic, parsed)
+			}
+		case "local", "path", "self", "terraform":
+			unsupported = append(unsupported, parsed)
+		default:
+			target, ok := parsed.resource()
+			if !ok {
+				unsupported = append(unsupported, parsed)
+				continue
+			}
+			if target.hasKey() && indexed == nil {
+				indexed = &target
+			}
+			if resource == nil {
+				resource = &target
+			}
+		}
+	}
+
+	switch {
+	case indexed != nil:
+		return &Reference{Kind: ResourceReference, Address: joinAddress(scope.Module, indexed.String())}, nil
+	case resource != nil:
+		address := resource.String()
+		if plan.isRepeated(scope.Module, address) {
+			// Without a static key or the key of the instance, e.g. g[each.value.team], any instance may be
+			// referenced and the unkeyed address would stand for all of them
+			if !instanceKeyed || scope.Key == nil {
+				reference := address
+				if len(dynamic) > 0 {
+					reference = dynamic[0].String()
+				}
+				return nil, &ReferenceError{Module: scope.Module, Reference: reference, Err: ErrUnknownInstance}
+			}
+			address += formatKey(scope.Key)
+		}
+		return &Reference{Kind: ResourceReference, Address: joinAddress(scope.Module, address)}, nil
+	case len(variables) > 0:
+		return plan.resolveVariable(scope, variables[0])
+	case len(outputs) > 0:
+		return plan.resolveOutput(scope, outputs[0])
+	case len(dynamic) > 0:
+		return nil, &ReferenceError{Module: scope.Module, Reference: dynamic[0].String(), Err: ErrUnsupportedReference}
+	case len(unsupported) > 0:
+		return nil, &ReferenceError{Module: scope.Module, Reference: unsupported[0].String(), Err: ErrUnsupportedReference}
+	}
+	return nil, &ReferenceError{Module: scope.Module, Err: ErrNoResourceReference}
+}
+
+// resolveVariable resolves an input variable through the expression of the module call, its default value
+// or, in the root module, through the plan variables
+func (plan *Plan) resolveVariable(scope Scope, variable traversal) (*Reference, error) {
+	if len(variable) < 2 {
+		return nil, &ReferenceError{Module: scope.Module, Reference: variable.String(), Err: ErrInvalidReference}
+	}
+	name := variable[1].name
+
+	module, ok := plan.Configuration.findModule(scope.Module)
+	if !ok {
+		return nil, &ReferenceError{Module: scope.Module, Reference: variable.String(), Err: ErrUnknownModule}
+	}
+	declaration, declared := module.Variables[name]
+
+	if scope.Module == "" {
+		if value, ok := plan.Variables[name]; ok {
+			return newConstantReference(scope, variable.String(), value.Value)
+		}
+	} else {
+		names := splitModuleAddress(scope.Module)
+		parentAddress := joinModuleAddress("", names[:len(names)-1]...)
+		parent, ok := plan.Configuration.findModule(parentAddress)
+		if !ok {
+			return nil, &ReferenceError{Module: parentAddress, Reference: variable.String(), Err: ErrUnknownModule}
+		}
+		if expression, ok := parent.ModuleCalls[names[len(names)-1]].Expressions[name]; ok {
+			return plan.ResolveExpression(Scope{Module: parentAddress}, expression)
+		}
+	}
+
+	if declared && declaration.Default != nil {
+		return newConstantReference(scope, variable.String(), declaration.Default)
+	}
+	return nil, &ReferenceError{Module: scope.Module, Reference: variable.String(), Err: ErrUnknownVariable}
+}
+
+// resolveOutput resolves the output of a called module through the expression of the output
+func (plan *Plan) resolveOutput(scope Scope, output traversal) (*Reference, error) {
+	if len(output) < 3 {
+		return nil, &ReferenceError{Module: scope.Module, Reference: output.String(), Err: ErrInvalidReference}
+	}
+
+	moduleAddress := joinModuleAddress(scope.Module, output[1].name)
+	module, ok := plan.Configuration.findModule(moduleAddress)
+	if !ok {
+		return nil, &ReferenceError{Module: scope.Module, Reference: output.String(), Err: ErrUnknownModule}
+	}
+
+	declaration, ok := module.Outputs[output[2].name]
+	if !ok {
+		return nil, &ReferenceError{Module: scope.Module, Reference: output.String(), Err: ErrUnknownOutput}
+	}
+	return plan.ResolveExpression(Scope{Module: moduleAddress}, declaration.Expression)
+}
+
+// isRepeated reports whether the resource at the module relative address uses count or for_each
+func (plan *Plan) isRepeated(moduleAddress, address string) bool {
+	module, ok := plan.Configuration.findModule(moduleAddress)
+	if !ok {
+		return false
+	}
+	for _, resource := range module.Resources {
+		if resource.Address == address {
+			return resource.CountExpression != nil || resource.ForEachExpression != nil
+		}
+	}
+	return false
+}
+
+func newConstantReference(scope Scope, reference string, value any) (*Reference, error) {
+	switch constant := value.(type) {
+	case string:
+		return &Reference{Kind: ConstantReference, Value: constant}, nil
+	case float64:
+		return &Reference{Kind: ConstantReference, Value: strconv.FormatFloat(constant, 'f', -1, 64)}, nil
+	case bool:
+		return &Reference{Kind: ConstantReference, Value: strconv.FormatBool(constant)}, nil
+	case nil:
+		return nil, &ReferenceError{Module: scope.Module, Reference: reference, Err: ErrEmptyExpression}
+	}
+	return nil, &ReferenceError{Module: scope.Module, Reference: reference, Err: ErrNoResourceReference}
+}
@@ -0,0 +1,122 @@
+package terraform
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// The plan does not record where resources are declared, so their blocks are looked up in the .tf files of the
+// configuration directory. Module calls are followed through their source when it is a local path, modules from
+// a registry or a remote source are not part of the configuration directory.
+
+var (
+	ErrRemoteModule   = errors.New("module source is not a local path")
+	ErrSourceNotFound = errors.New("resource block is not found in the configuration files")
+)
+
+// SourceLocation is the position of the block declaring a resource
+type SourceLocation struct {
+	// File is the path of the .tf file, relative to the working directory like the configuration directory
+	File string
+	// Line is the 1-based line number of the block
+	Line int
+}
+
+// Sources finds the declarations of resources in the configuration directory of a plan
+type Sources struct {
+	dir    string
+	plan   *Plan
+	blocks map[string]map[string]SourceLocation
+}
+
+var blockPattern = regexp.MustCompile(`^\s*(resource|data)\s+"([^"]+)"\s+"([^"]+)"`)
+
+func NewSources(dir string, plan *Plan) *Sources {
+	return &Sources{dir: dir, plan: plan, blocks: map[string]map[string]SourceLocation{}}
+}
+
+// Find returns the location of the block declaring the resource instance with the given address
+func (sources *Sources) Find(address string) (*SourceLocation, error) {
+	parsed, err := ParseAddress(address)
+	if err != nil {
+		return nil, err
+	}
+
+	dir, module := sources.dir, &sources.plan.Configuration.RootModule
+	for _, instance := range parsed.Module {
+		call, ok := module.ModuleCalls[instance.Name]
+		if !ok {
+			return nil, ErrUnknownModule
+		}
+		if !strings.HasPrefix(call.Source, "./") && !strings.HasPrefix(call.Source, "../") {
+			return nil, ErrRemoteModule
+		}
+		dir, module = filepath.Join(dir, call.Source), &call.Module
+	}
+
+	blocks, err := sources.moduleBlocks(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	key := string(parsed.Type) + "." + parsed.Name
+	if parsed.Mode == DataResourceMode {
+		key = "data." + key
+	}
+	location, ok := blocks[key]
+	if !ok {
+		return nil, ErrSourceNotFound
+	}
+	return &location, nil
+}
+
+// moduleBlocks returns the location of each resource and data block in the .tf files of the directory
+func (sources *Sources) moduleBlocks(dir string) (map[string]SourceLocation, error) {
+	if blocks, ok := sources.blocks[dir]; ok {
+		return blocks, nil
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.tf"))
+	if err != nil {
+		return nil, err
+	}
+	slices.Sort(files)
+
+	blocks := map[string]SourceLocation{}
+	for _, file := range files {
+		if err = scanBlocks(file, blocks); err != nil {
+			return nil, err
+		}
+	}
+	sources.blocks[dir] = blocks
+	return blocks, nil
+}
+
+func scanBlocks(file string, blocks map[string]SourceLocation) error {
+	handle, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer handle.Close()
+
+	scanner := bufio.NewScanner(handle)
+	for line := 1; scanner.Scan(); line++ {
+		match := blockPattern.FindStringSubmatch(scanner.Text())
+		if match == nil {
+			continue
+		}
+		key := match[2] + "." + match[3]
+		if match[1] == "data" {
+			key = "data." + key
+		}
+		if _, ok := blocks[key]; !ok {
+			blocks[key] = SourceLocation{File: file, Line: line}
+		}
+	}
+	return scanner.Err()
+}
@@ -14,6 +14,13 @@ type Plan struct {
 	ResourceChanges []ResourceChange `json:"resource_changes"`
 	PriorState      PriorState       `json:"prior_state"`
 	Configuration   Configuration    `json:"configuration"`
+	Variables       PlanVariables    `json:"variables"`
+}
+
+type PlanVariables map[string]PlanVariable
+
+type PlanVariable struct {
+	Value any `json:"value"`
 }
 
 type PriorState struct {
@@ -25,7 +32,9 @@ type PriorStateValues struct {
 }
 
 type PriorStateModule struct {
-	Resources []PriorStateResource `json:"resources"`
+	Address      string               `json:"address"`
+	Resources    []PriorStateResource `json:"resources"`
+	ChildModules []PriorStateModule   `json:"child_modules"`
 }
 
 type PriorStateResource struct {
@@ -42,6 +51,10 @@ type PriorStateResourceValues struct {
 	OwnerUserGroupID *string `json:"owner_user_group_id"`
 	GroupID          *string `json:"group_id"`
 	UserID           *string `json:"user_id"`
+	Name             string  `json:"name"`
+	Project          string  `json:"project"`
+	ServiceName      string  `json:"service_name"`
+	TopicName        string  `json:"topic_name"`
 }
 
 type Configuration struct {
@@ -49,14 +62,37 @@ type Configuration struct {
 }
 
 type ConfigurationModule struct {
-	Resources []ConfigurationResource `json:"resources"`
+	Resources   []ConfigurationResource          `json:"resources"`
+	ModuleCalls map[string]ModuleCall            `json:"module_calls"`
+	Outputs     map[string]ConfigurationOutput   `json:"outputs"`
+	Variables   map[string]ConfigurationVariable `json:"variables"`
+}
+
+type ModuleCall struct {
+	Source      string                 `json:"source"`
+	Expressions map[string]*Expression `json:"expressions"`
+	Module      ConfigurationModule    `json:"module"`
+}
+
+type ConfigurationOutput struct {
+	Expression *Expression `json:"expression"`
+}
+
+type ConfigurationVariable struct {
+	Default any `json:"default"`
 }
 
 type ConfigurationResource struct {
-	Type        ResourceType `json:"type"`
-	Name        string       `json:"name"`
-	Address     string       `json:"address"`
-	Expressions Expressions  `json:"expressions"`
+	Mode              ResourceMode `json:"mode"`
+	Type              ResourceType `json:"type"`
+	Name              string       `json:"name"`
+	Address           string       `json:"address"`
+	Expressions       Expressions  `json:"expressions"`
+	CountExpression   *Expression  `json:"count_expression"`
+	ForEachExpression *Expression  `json:"for_each_expression"`
+	// Module is the address of the module the resource is declared in, empty for the root module.
+	// Addresses and references in the configuration are relative to this module.
+	Module string `json:"-"`
 }
 
 type Expressions struct {
@@ -67,14 +103,17 @@ type Expressions struct {
 }
 
 type Expression struct {
-	References []string `json:"references"`
+	References    []string `json:"references"`
+	ConstantValue any      `json:"constant_value"`
 }
 
 type ResourceChange struct {
-	Type    ResourceType `json:"type"`
-	Name    string       `json:"name"`
-	Address string       `json:"address"`
-	Change  Change       `json:"change"`
+	Type          ResourceType `json:"type"`
+	Name          string       `json:"name"`
+	Address       string       `json:"address"`
+	ModuleAddress string       `json:"module_address"`
+	Index         any          `json:"index"`
+	Change        Change       `json:"change"`
 }
 
 type Change struct {
@@ -95,6 +134,18 @@ type ResourceChangeValues struct {
 	Project          *string       `json:"project"`
 	ServiceName      *string       `json:"service_name"`
 	TopicName        *string       `json:"topic_name"`
+	Name             *string       `json:"name"`
+	// SubjectName, Schema and CompatibilityLevel are the arguments of a schema of the schema registry
+	SubjectName        *string `json:"subject_name"`
+	Schema             *string `json:"schema"`
+	CompatibilityLevel *string `json:"compatibility_level"`
+	// Topic is the topic pattern of an aiven_kafka_acl, ResourceName, ResourceType and PatternType select the
+	// resources of an aiven_kafka_native_acl, which allows or denies access by PermissionType
+	Topic          *string `json:"topic"`
+	ResourceName   *string `json:"resource_name"`
+	ResourceType   *string `json:"resource_type"`
+	PatternType    *string `json:"pattern_type"`
+	PermissionType *string `json:"permission_type"`
 }
 
 type AccessData struct {
@@ -103,12 +154,35 @@ type AccessData struct {
 	Acls        []AccessACL `json:"acls"`
 }
 
+// AccessACL is a Kafka ACL granted by an aiven_governance_access, selecting resources of the ResourceType by the
+// ResourceName with the PatternType like an aiven_kafka_native_acl
 type AccessACL struct {
-	ResourceName string `json:"resource_name"`
+	ResourceName   string `json:"resource_name"`
+	ResourceType   string `json:"resource_type"`
+	PatternType    string `json:"pattern_type"`
+	Operation      string `json:"operation"`
+	PermissionType string `json:"permission_type"`
+	Principal      string `json:"principal"`
+	Host           string `json:"host"`
 }
 
 type AfterUnknown struct {
 	OwnerUserGroupID *bool `json:"owner_user_group_id"`
+	// Topic, ResourceName and ResourceType are known after apply when a Kafka ACL refers to another resource
+	Topic        *bool `json:"topic"`
+	ResourceName *bool `json:"resource_name"`
+	ResourceType *bool `json:"resource_type"`
+	// AccessData is true when the access_data blocks of an aiven_governance_access are known after apply as a whole
+	AccessData Unknown `json:"access_data"`
+}
+
+// Unknown tells whether a value is known after apply as a whole. A block with only some values known after apply is
+// described by a structure of those values rather than true, and is not unknown.
+type Unknown bool
+
+func (unknown *Unknown) UnmarshalJSON(data []byte) error {
+	*unknown = string(data) == "true"
+	return nil
 }
 
 type Tag struct {
@@ -122,7 +196,11 @@ type ActionType string
 
 const (
 	AivenKafkaTopic                  ResourceType = "aiven_kafka_topic"
+	AivenKafkaSchema                 ResourceType = "aiven_kafka_schema"
+	AivenKafkaACL                    ResourceType = "aiven_kafka_acl"
+	AivenKafkaNativeACL              ResourceType = "aiven_kafka_native_acl"
 	AivenExternalIdentity            ResourceType = "aiven_external_identity"
+	AivenOrganizationUserGroup       ResourceType = "aiven_organization_user_group"
 	AivenOrganizationUserGroupMember ResourceType = "aiven_organization_user_group_member"
 	AivenGovernanceAccess            ResourceType = "aiven_governance_access"
 )
@@ -131,6 +209,9 @@ const (
 	CreateAction ActionType = "create"
 	UpdateAction ActionType = "update"
 	DeleteAction ActionType = "delete"
+	NoOpAction   ActionType = "no-op"
+	ReadAction   ActionType = "read"
+	ForgetAction ActionType = "forget"
 )
 
 func NewPlan(path string) (*Plan, error) {
@@ -1,109 +1,456 @@
 package main
 
 import (
+	"cmp"
+	"errors"
+	"flag"
+	"fmt"
 	"log"
 	"maps"
 	"os"
+	"runtime/debug"
 	"slices"
+	"strings"
+	"time"
 
+	"aiven/terraform/governance/compliance/checker/internal/directory"
+	"aiven/terraform/governance/compliance/checker/internal/identity"
 	"aiven/terraform/governance/compliance/checker/internal/input"
+	"aiven/terraform/governance/compliance/checker/internal/policy"
 	"aiven/terraform/governance/compliance/checker/internal/terraform"
 )
 
 type ResultError struct {
-	Error   string          `json:"error"`
-	Address string          `json:"address"`
-	Tags    []terraform.Tag `json:"tags"`
+	Error    string          `json:"error"`
+	Address  string          `json:"address"`
+	Tags     []terraform.Tag `json:"tags"`
+	Severity Severity        `json:"severity"`
+	// RuleID, Remediation and DocsURL come from the rule of the error, see rules.go
+	RuleID      string `json:"rule_id"`
+	Remediation string `json:"remediation"`
+	DocsURL     string `json:"docs_url"`
+	// OwnerGroup is set on approval errors, its members can approve the change
+	OwnerGroup *OwnerGroup `json:"owner_group,omitempty"`
+	// Action is the kind of change of the resource, used by the formats grouping errors per resource
+	Action terraform.ChangeKind `json:"-"`
+}
+
+type OwnerGroup struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// EligibleApprovers are the external user IDs of the members of the group
+	EligibleApprovers []string `json:"eligible_approvers"`
 }
 
 type Check func(
 	terraform.ResourceChange,
 	*terraform.PriorStateResource,
-	[]*terraform.PriorStateResource,
+	Approvals,
 	*terraform.Plan,
 ) CheckResult
 
-type ResourceErrorKey struct {
-	resource string
-	error    string
+// Approvals are the approvers of the change, stale approvals are given for an earlier commit than the latest one
+// and don't count
+type Approvals struct {
+	Approvers []*terraform.PriorStateResource
+	Stale     []StaleApproval
 }
 
-var checks = map[terraform.ResourceType][]Check{
-	terraform.AivenKafkaTopic:                  {changeIsRequestedByOwner, changeIsApprovedByOwner},
-	terraform.AivenExternalIdentity:            {},
-	terraform.AivenOrganizationUserGroupMember: {},
-	terraform.AivenGovernanceAccess:            {governanceAccessCheck},
+type StaleApproval struct {
+	Approver *terraform.PriorStateResource
+	// CommitID is the commit the approval is given for
+	CommitID string
+}
+
+// shortCommitLength is the length commit IDs are abbreviated to in errors, like git does
+const shortCommitLength = 7
+
+type ResourceErrorKey struct {
+	address string
+	error   string
+	// ownerGroup is the ID of the owner group of an approval error, the old and the new owner of a resource both
+	// approve a change of the owner
+	ownerGroup string
 }
 
+// Exit codes of the -exit-code mode, without it the checker exits with 1 on errors and 0 otherwise
+const (
+	exitCompliant     = 0
+	exitNonCompliant  = 2
+	exitWarningsOnly  = 3
+	exitInvalidInput  = 4
+	exitInternalError = 5
+)
+
 func main() {
 	logger := log.New(os.Stderr, "", 0)
 
-	args, inputErr := input.NewInput(os.Args[1:])
-	if inputErr != nil {
-		logger.Fatal(inputErr)
+	args, err := input.NewInput(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	exit := exiter{logger: logger, exitCodes: args.ExitCode}
+	defer exit.recover()
+	if err != nil {
+		exit.fatal(exitInvalidInput, err)
 	}
 
 	plan, err := terraform.NewPlan(args.Plan)
 	if err != nil {
-		logger.Fatal(err)
+		exit.fatal(exitInvalidInput, err)
 	}
 
-	result := Result{Ok: true, Errors: []ResultError{}}
+	checkPolicy := &defaultPolicy
+	if args.Policy != "" {
+		if checkPolicy, err = policy.Load(args.Policy); err != nil {
+			exit.fatal(exitInvalidInput, err)
+		}
+	}
+
+	checks, err := newPolicyChecks(checkPolicy)
+	if err != nil {
+		exit.fatal(exitInvalidInput, err)
+	}
+
+	failOn, err := parseSeverity(args.FailOn)
+	if err != nil {
+		exit.fatal(exitInvalidInput, fmt.Errorf("invalid fail-on threshold: %w", err))
+	}
+
+	formatter, err := findFormatter(args.Format)
+	if err != nil {
+		exit.fatal(exitInvalidInput, err)
+	}
+
+	var diagnostics Diagnostics
+	snapshot, conflicts, err := mergeUserSources(args, plan)
+	if err != nil {
+		exit.fatal(exitInvalidInput, err)
+	}
+	diagnostics.IdentityConflicts = conflicts
 
 	requester := findExternalIdentity(args.Requester, plan)
-	approvers := findApprovers(args.Approvers, args.Requester, plan)
+	diagnostics.UnresolvedIdentities = findUnresolvedIdentities(args.Requester, args.Approvers, plan)
+	diagnostics.IgnoredSelfApprovals = findSelfApprovals(args.Approvers, args.Requester, plan)
+
+	result := evaluate(plan, requester, findApprovals(args, plan), checks, failOn)
+	if requester == nil && args.Strict {
+		// the change as a whole fails, not only the resources whose checks need the requester
+		result.add(newRequesterUnresolvedError(args.Requester), failOn)
+	}
+	if !diagnostics.empty() {
+		result.Diagnostics = &diagnostics
+	}
+	if snapshot != nil {
+		result.Directory = newDirectoryReport(snapshot, time.Now())
+	}
+
+	if args.ReportAll {
+		result.reportAll()
+	}
+
+	context := FormatContext{PlanPath: args.Plan}
+	if args.ConfigDir != "" {
+		context.Sources = terraform.NewSources(args.ConfigDir, plan)
+	}
+
+	if args.Format == "github" {
+		if err = writeGitHubEnvironmentFiles(result, context); err != nil {
+			exit.fatal(exitInternalError, err)
+		}
+	}
+
+	if _, err = fmt.Fprintln(os.Stdout, formatter(result, context)); err != nil {
+		exit.fatal(exitInternalError, fmt.Errorf("failed to write the report: %w", err))
+	}
+
+	if args.ExitCode {
+		os.Exit(resultExitCode(result))
+	}
+}
+
+// exiter ends the process on errors, with the exit codes of the -exit-code mode if it is enabled
+type exiter struct {
+	logger    *log.Logger
+	exitCodes bool
+}
+
+// recover turns a panic into an internal error, a crash on an unexpected plan must not look like a non-compliant
+// result, which the Go runtime exits with as well
+func (exiter exiter) recover() {
+	if recovered := recover(); recovered != nil {
+		exiter.fatal(exitInternalError, fmt.Errorf("internal error: %v\n%s", recovered, debug.Stack()))
+	}
+}
+
+// fatal logs the error and exits with the code, or with 1 if exit codes are not enabled
+func (exiter exiter) fatal(code int, err error) {
+	if !exiter.exitCodes {
+		code = 1
+	}
+	exiter.logger.Print(err)
+	os.Exit(code)
+}
 
+func resultExitCode(result Result) int {
+	switch {
+	case !result.Ok:
+		return exitNonCompliant
+	case len(result.Warnings) > 0:
+		return exitWarningsOnly
+	}
+	return exitCompliant
+}
+
+// findApprovals finds the approvers and the stale approvals of the change, the requester can't approve it
+func findApprovals(args *input.Input, plan *terraform.Plan) Approvals {
+	return Approvals{
+		Approvers: findApprovers(args.Approvers, args.Requester, plan),
+		Stale:     findStaleApprovals(args.StaleApprovals, args.Requester, plan),
+	}
+}
+
+// evaluate runs the checks for every resource change of the plan
+func evaluate(
+	plan *terraform.Plan,
+	requester *terraform.PriorStateResource,
+	approvals Approvals,
+	checks *PolicyChecks,
+	failOn Severity,
+) Result {
+	result := Result{Ok: true, Errors: []ResultError{}, Warnings: []ResultError{}}
+
+	var resultErrors []ResultError
 	for _, resourceChange := range plan.ResourceChanges {
-		errors := validateResourceChange(resourceChange, requester, approvers, plan)
-		result.Errors = append(result.Errors, errors...)
+		for _, evaluation := range validateResourceChange(resourceChange, requester, approvals, plan, checks) {
+			resultErrors = append(resultErrors, evaluation.Errors...)
+			result.addEvaluation(evaluation, failOn)
+		}
 	}
 
-	// result.Ok is the source of truth for the result of the validation
-	if len(result.Errors) > 0 {
-		result.Ok = false
+	for _, resultError := range uniqueResultErrors(resultErrors) {
+		// result.Ok is the source of truth for the result of the validation
+		result.add(resultError, failOn)
 	}
+	return result
+}
 
-	logger.SetOutput(os.Stdout)
-	logger.Println(result.toJSON())
+// writeGitHubEnvironmentFiles writes the report in markdown to the job summary and in JSON to the result output
+// of the step, the files are only set when running in GitHub Actions
+func writeGitHubEnvironmentFiles(result Result, context FormatContext) error {
+	if err := appendToFile(os.Getenv("GITHUB_STEP_SUMMARY"), formatMarkdown(result, context)); err != nil {
+		return fmt.Errorf("failed to write the step summary: %w", err)
+	}
+	// the JSON report is a single line, so it doesn't need a heredoc delimiter
+	if err := appendToFile(os.Getenv("GITHUB_OUTPUT"), "result="+result.toJSON()+"\n"); err != nil {
+		return fmt.Errorf("failed to write the step output: %w", err)
+	}
+	return nil
+}
+
+func appendToFile(path, content string) error {
+	if path == "" {
+		return nil
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	_, err = file.WriteString(content)
+	return errors.Join(err, file.Close())
 }
 
 func validateResourceChange(
 	resourceChange terraform.ResourceChange,
 	requester *terraform.PriorStateResource,
-	approvers []*terraform.PriorStateResource,
+	approvals Approvals,
 	plan *terraform.Plan,
-) []ResultError {
+	checks *PolicyChecks,
+) []CheckEvaluation {
+	evaluation := CheckEvaluation{
+		Address:  resourceChange.Address,
+		Type:     resourceChange.Type,
+		Action:   resourceChange.Change.Kind(),
+		Errors:   []ResultError{},
+		Evidence: []Evidence{},
+	}
 
-	resourceChecks, ok := checks[resourceChange.Type]
-	if !ok {
+	resourceChecks, _ := checks.forResourceChange(resourceChange)
+	if len(resourceChecks) == 0 {
 		// no checks for this resource type
-		return []ResultError{}
+		evaluation.Outcome = OutcomeSkipped
+		return []CheckEvaluation{evaluation}
 	}
 
-	var checkErrors = make([]ResultError, 0)
+	evaluations := make([]CheckEvaluation, 0, len(resourceChecks))
+	// the requester can't approve their own change
+	requesterLogins := findUserLogins(requester, plan)
 
-	//  run the checks and collect errors
+	//  run the checks and collect their outcome
 	for _, check := range resourceChecks {
-		singleCheckResult := check(resourceChange, requester, approvers, plan)
+		checkEvaluation := evaluation
+		checkEvaluation.Check = check.Name
+		checkEvaluation.Outcome = OutcomePassed
+		checkEvaluation.Errors = []ResultError{}
+		checkEvaluation.Evidence = []Evidence{}
+
+		singleCheckResult := check.Check(resourceChange, requester, approvals, plan)
 		if !singleCheckResult.ok {
-			checkErrors = append(checkErrors, singleCheckResult.errors...)
+			checkEvaluation.Outcome = OutcomeFailed
+			for _, err := range singleCheckResult.errors {
+				err.Severity = check.Severity
+				err.Action = evaluation.Action
+				checkEvaluation.Errors = append(checkEvaluation.Errors, err.withoutApprovers(requesterLogins))
+			}
 		}
+		checkEvaluation.Errors = uniqueResultErrors(checkEvaluation.Errors)
+		// the same requirement is satisfied by the same user before and after an update
+		for _, evidence := range singleCheckResult.evidence {
+			if !slices.Contains(checkEvaluation.Evidence, evidence) {
+				checkEvaluation.Evidence = append(checkEvaluation.Evidence, evidence)
+			}
+		}
+		evaluations = append(evaluations, checkEvaluation)
 	}
 
-	// Remove duplicate errors
-	errorMap := make(map[ResourceErrorKey]ResultError)
-	for _, err := range checkErrors {
-		errorMap[ResourceErrorKey{resource: resourceChange.Name, error: err.Error}] = err
+	return evaluations
+}
+
+// Remove duplicate errors, errors are the same if they have the same message and owner group for the same resource
+// address and the most severe one wins, the last one if they are as severe. The errors are sorted by address, rule
+// ID and message so that the report is the same between runs.
+func uniqueResultErrors(resultErrors []ResultError) []ResultError {
+	seen := make(map[ResourceErrorKey]int)
+	unique := make([]ResultError, 0, len(resultErrors))
+	for _, err := range resultErrors {
+		key := ResourceErrorKey{address: err.Address, error: err.Error}
+		if err.OwnerGroup != nil {
+			key.ownerGroup = err.OwnerGroup.ID
+		}
+		if i, ok := seen[key]; ok {
+			if err.Severity.AtLeast(unique[i].Severity) {
+				unique[i] = err
+			}
+			continue
+		}
+		seen[key] = len(unique)
+		unique = append(unique, err)
+	}
+
+	slices.SortStableFunc(unique, func(a, b ResultError) int {
+		return cmp.Or(
+			strings.Compare(a.Address, b.Address),
+			strings.Compare(a.RuleID, b.RuleID),
+			strings.Compare(a.Error, b.Error),
+		)
+	})
+	return unique
+}
+
+// mergeUserSources merges the identities file and the directory snapshot into the prior state, the snapshot is nil
+// if no directory is provided
+func mergeUserSources(args *input.Input, plan *terraform.Plan) (*directory.Snapshot, []IdentityConflict, error) {
+	var conflicts []IdentityConflict
+	if args.Identities != "" {
+		identities, err := identity.Load(args.Identities)
+		if err != nil {
+			return nil, nil, err
+		}
+		conflicts = mergeIdentities(plan, identities)
+	}
+
+	if args.Directory == "" {
+		return nil, conflicts, nil
+	}
+	snapshot, err := directory.Load(args.Directory)
+	if err != nil {
+		return nil, nil, err
+	}
+	conflicts = append(conflicts, mergeIdentities(plan, snapshot.Identities())...)
+	mergeDirectory(plan, snapshot)
+	return snapshot, conflicts, nil
+}
+
+// mergeIdentities adds the aliases of the identities file to the prior state as aiven_external_identity resources,
+// so that they resolve like the identities in state. An alias mapped to another internal user ID already keeps
+// its first mapping, state first, and is reported as a conflict.
+func mergeIdentities(plan *terraform.Plan, identities []identity.Identity) []IdentityConflict {
+	userIDs := map[string][]string{}
+	for _, resource := range plan.PriorStateResources() {
+		alias, userID := resource.Values.ExternalUserID, resource.Values.InternalUserID
+		if resource.Type == terraform.AivenExternalIdentity && !slices.Contains(userIDs[alias], userID) {
+			userIDs[alias] = append(userIDs[alias], userID)
+		}
 	}
 
-	// Convert the map back into a slice
-	return slices.Collect(maps.Values(errorMap))
+	root := &plan.PriorState.Values.RootModule
+	for _, mapping := range identities {
+		for _, alias := range mapping.Aliases {
+			if len(userIDs[alias]) == 0 {
+				// the identity is not declared in the configuration, members refer to the internal user ID directly
+				root.Resources = append(root.Resources, terraform.PriorStateResource{
+					Type:   terraform.AivenExternalIdentity,
+					Name:   alias,
+					Values: terraform.PriorStateResourceValues{ExternalUserID: alias, InternalUserID: mapping.InternalUserID},
+				})
+			}
+			if !slices.Contains(userIDs[alias], mapping.InternalUserID) {
+				userIDs[alias] = append(userIDs[alias], mapping.InternalUserID)
+			}
+		}
+	}
 
+	conflicts := []IdentityConflict{}
+	for _, alias := range slices.Sorted(maps.Keys(userIDs)) {
+		if len(userIDs[alias]) > 1 {
+			conflicts = append(conflicts, IdentityConflict{Alias: alias, InternalUserIDs: userIDs[alias]})
+		}
+	}
+	return conflicts
+}
+
+// mergeDirectory adds the user groups and memberships of the directory snapshot to the prior state, so that
+// memberships managed outside of Terraform count like the ones in state
+func mergeDirectory(plan *terraform.Plan, snapshot *directory.Snapshot) {
+	groups, members := map[string]bool{}, map[[2]string]bool{}
+	for _, resource := range plan.PriorStateResources() {
+		switch {
+		case resource.Type == terraform.AivenOrganizationUserGroup && resource.Values.GroupID != nil:
+			groups[*resource.Values.GroupID] = true
+		case resource.Type == terraform.AivenOrganizationUserGroupMember &&
+			resource.Values.GroupID != nil && resource.Values.UserID != nil:
+			members[[2]string{*resource.Values.GroupID, *resource.Values.UserID}] = true
+		}
+	}
+
+	root := &plan.PriorState.Values.RootModule
+	for _, group := range snapshot.UserGroups {
+		if !groups[group.UserGroupID] {
+			groups[group.UserGroupID] = true
+			root.Resources = append(root.Resources, terraform.PriorStateResource{
+				Type:   terraform.AivenOrganizationUserGroup,
+				Name:   group.UserGroupName,
+				Values: terraform.PriorStateResourceValues{GroupID: &group.UserGroupID, Name: group.UserGroupName},
+			})
+		}
+		for _, member := range group.Members {
+			key := [2]string{group.UserGroupID, member.UserID}
+			if member.UserID == "" || members[key] {
+				continue
+			}
+			members[key] = true
+			root.Resources = append(root.Resources, terraform.PriorStateResource{
+				Type:   terraform.AivenOrganizationUserGroupMember,
+				Name:   member.UserID,
+				Values: terraform.PriorStateResourceValues{GroupID: &group.UserGroupID, UserID: &member.UserID},
+			})
+		}
+	}
 }
 
 // Finds external identity resource for a given user ID from the current (prior) state
 func findExternalIdentity(userID string, plan *terraform.Plan) *terraform.PriorStateResource {
-	for _, resource := range plan.PriorState.Values.RootModule.Resources {
+	for _, resource := range plan.PriorStateResources() {
 		if resource.Type == terraform.AivenExternalIdentity && userID == resource.Values.ExternalUserID {
 			return &resource
 		}
@@ -116,31 +463,101 @@ func findApprovers(approverIDs []string, requesterID string, plan *terraform.Pla
 	for _, approverID := range approverIDs {
 		approver := findExternalIdentity(approverID, plan)
 		// requester can't approve their own request
-		if approver != nil && requesterID != approverID {
+		if approver != nil && !isRequester(approverID, requesterID, plan) {
 			approvers = append(approvers, approver)
 		}
 	}
 	return approvers
 }
 
-// Find the owner address from the proposed / planned Terraform configuration
-func findOwnerAddressFromConfig(address string, plan *terraform.Plan) *string {
-	for _, resource := range plan.Configuration.RootModule.Resources {
-		if resource.Address == address {
-			return &resource.Expressions.OwnerUserGroupID.References[1]
+// isRequester reports whether the approver is the requester, by their user ID or, as the aliases of the identities
+// file and the directory give a user several user IDs, by the internal user ID they resolve to
+func isRequester(approverID string, requesterID string, plan *terraform.Plan) bool {
+	if requesterID == "" {
+		return false
+	}
+	if approverID == requesterID {
+		return true
+	}
+	approver, requester := findExternalIdentity(approverID, plan), findExternalIdentity(requesterID, plan)
+	return approver != nil && requester != nil && approver.Values.InternalUserID != "" &&
+		approver.Values.InternalUserID == requester.Values.InternalUserID
+}
+
+// findUnresolvedIdentities finds the requester and approvers without an external identity
+func findUnresolvedIdentities(requesterID string, approverIDs []string, plan *terraform.Plan) []UnresolvedIdentity {
+	var unresolved []UnresolvedIdentity
+	if requesterID != "" && findExternalIdentity(requesterID, plan) == nil {
+		unresolved = append(unresolved, UnresolvedIdentity{User: requesterID, Role: RoleRequester})
+	}
+	for _, approverID := range approverIDs {
+		if approverID != "" && approverID != requesterID && findExternalIdentity(approverID, plan) == nil {
+			unresolved = append(unresolved, UnresolvedIdentity{User: approverID, Role: RoleApprover})
 		}
 	}
-	return nil
+	return unresolved
 }
 
-// Find the user address from the proposed / planned Terraform configuration
-func findUserAddressFromConfig(address string, plan *terraform.Plan) *string {
-	for _, resource := range plan.Configuration.RootModule.Resources {
-		if resource.Address == address {
-			return &resource.Expressions.InternalUserID.References[1]
+// findSelfApprovals finds the approvals of the requester, under their own user ID or another alias, which
+// findApprovers ignores
+func findSelfApprovals(approverIDs []string, requesterID string, plan *terraform.Plan) []string {
+	var selfApprovals []string
+	for _, approverID := range approverIDs {
+		if isRequester(approverID, requesterID, plan) && !slices.Contains(selfApprovals, approverID) {
+			selfApprovals = append(selfApprovals, approverID)
 		}
 	}
-	return nil
+	return selfApprovals
+}
+
+// findStaleApprovals finds the external identities of the approvers whose approval is given for an earlier commit,
+// keyed by their user ID
+func findStaleApprovals(commitIDs map[string]string, requesterID string, plan *terraform.Plan) []StaleApproval {
+	var staleApprovals []StaleApproval
+	for _, approverID := range slices.Sorted(maps.Keys(commitIDs)) {
+		approver := findExternalIdentity(approverID, plan)
+		if approver != nil && !isRequester(approverID, requesterID, plan) {
+			staleApprovals = append(staleApprovals, StaleApproval{Approver: approver, CommitID: commitIDs[approverID]})
+		}
+	}
+	return staleApprovals
+}
+
+// Find the configuration resource declaring the resource instance with the given address,
+// together with the scope its expressions are evaluated in
+func findConfigurationResource(
+	address string,
+	plan *terraform.Plan,
+) (*terraform.ConfigurationResource, terraform.Scope, error) {
+	parsed, err := terraform.ParseAddress(address)
+	if err != nil {
+		return nil, terraform.Scope{}, fmt.Errorf("%w: %s", err, address)
+	}
+
+	for _, resource := range plan.ConfigurationResources() {
+		if resource.Matches(parsed) {
+			return &resource, terraform.Scope{Module: resource.Module, Key: parsed.Key}, nil
+		}
+	}
+	return nil, terraform.Scope{}, fmt.Errorf("%s is not declared in the configuration", address)
+}
+
+// Find the owner reference from the proposed / planned Terraform configuration
+func findOwnerAddressFromConfig(address string, plan *terraform.Plan) (*terraform.Reference, error) {
+	resource, scope, err := findConfigurationResource(address, plan)
+	if err != nil {
+		return nil, err
+	}
+	return plan.ResolveExpression(scope, resource.Expressions.OwnerUserGroupID)
+}
+
+// Find the user reference from the proposed / planned Terraform configuration
+func findUserAddressFromConfig(address string, plan *terraform.Plan) (*terraform.Reference, error) {
+	resource, scope, err := findConfigurationResource(address, plan)
+	if err != nil {
+		return nil, err
+	}
+	return plan.ResolveExpression(scope, resource.Expressions.InternalUserID)
 }
 
 // Check if the user is a member of the owner group in the proposed / planned Terraform configuration
@@ -149,39 +566,56 @@ func isUserGroupMemberInConfig(
 	user *terraform.PriorStateResource,
 	plan *terraform.Plan,
 ) bool {
-	ownerAddress := findOwnerAddressFromConfig(resourceChange.Address, plan)
-	if user == nil || ownerAddress == nil {
+	owner, ownerErr := findOwnerAddressFromConfig(resourceChange.Address, plan)
+	if user == nil || ownerErr != nil {
 		return false
 	}
 
-	userAddress := findUserAddressFromConfig(user.Address, plan)
-	if userAddress == nil {
-		return false
-	}
+	// the identity may be missing from the configuration, members can still refer to the internal user ID directly
+	userReference, _ := findUserAddressFromConfig(user.Address, plan)
 
-	for _, resource := range plan.Configuration.RootModule.Resources {
-		if resource.Type == terraform.AivenOrganizationUserGroupMember {
-			groupReference := resource.Expressions.GroupID.References[1]
-			userReference := resource.Expressions.UserID.References[1]
-			if groupReference == *ownerAddress && userReference == *userAddress {
-				return true
-			}
+	for _, resource := range plan.ConfigurationResources() {
+		if resource.Type != terraform.AivenOrganizationUserGroupMember {
+			continue
+		}
+		scope := terraform.Scope{Module: resource.Module}
+		groupReference, groupErr := plan.ResolveExpression(scope, resource.Expressions.GroupID)
+		memberReference, memberErr := plan.ResolveExpression(scope, resource.Expressions.UserID)
+		if groupErr != nil || memberErr != nil {
+			continue
+		}
+		if *groupReference == *owner && isSameUser(memberReference, userReference, user) {
+			return true
 		}
 	}
 	return false
 }
 
-// Check if the user is a member of the owner group in the current Terraform state
+// Check if the user of a group member refers to the given user, either through the configuration or by its ID
+func isSameUser(
+	member *terraform.Reference,
+	userReference *terraform.Reference,
+	user *terraform.PriorStateResource,
+) bool {
+	if member.Kind == terraform.ConstantReference {
+		return member.Value != "" && member.Value == user.Values.InternalUserID
+	}
+	return userReference != nil && *member == *userReference
+}
+
+// Check if the user is a member of the owner group in the current Terraform state, which includes the memberships
+// of the directory snapshot
 func isUserGroupMemberInState(
 	resourceWithOwner *terraform.ResourceChangeValues,
 	user *terraform.PriorStateResource,
 	plan *terraform.Plan,
 ) bool {
-	if resourceWithOwner == nil {
+	if resourceWithOwner == nil || resourceWithOwner.OwnerUserGroupID == nil || user == nil {
 		return false
 	}
-	for _, resource := range plan.PriorState.Values.RootModule.Resources {
-		if resource.Type == terraform.AivenOrganizationUserGroupMember {
+	for _, resource := range plan.PriorStateResources() {
+		if resource.Type == terraform.AivenOrganizationUserGroupMember &&
+			resource.Values.GroupID != nil && resource.Values.UserID != nil {
 			if *resource.Values.GroupID == *resourceWithOwner.OwnerUserGroupID &&
 				*resource.Values.UserID == user.Values.InternalUserID {
 				return true
@@ -190,3 +624,150 @@ func isUserGroupMemberInState(
 	}
 	return false
 }
+
+// findTopics returns the topics of the plan: the changes of the topics managed by the configuration, followed by
+// the topics only in the prior state, such as data sources, as no-op changes
+func findTopics(plan *terraform.Plan) []terraform.ResourceChange {
+	topics := []terraform.ResourceChange{}
+	changed := map[string]bool{}
+	for _, resourceChange := range plan.ResourceChanges {
+		if resourceChange.Type == terraform.AivenKafkaTopic {
+			topics = append(topics, resourceChange)
+			changed[resourceChange.Address] = true
+		}
+	}
+
+	for _, resource := range plan.PriorStateResources() {
+		if resource.Type != terraform.AivenKafkaTopic || changed[resource.Address] {
+			continue
+		}
+		values := &terraform.ResourceChangeValues{
+			Tag:              &resource.Values.Tag,
+			OwnerUserGroupID: resource.Values.OwnerUserGroupID,
+			Project:          &resource.Values.Project,
+			ServiceName:      &resource.Values.ServiceName,
+			TopicName:        &resource.Values.TopicName,
+		}
+		topics = append(topics, terraform.ResourceChange{
+			Type:    resource.Type,
+			Name:    resource.Name,
+			Address: resource.Address,
+			Change:  terraform.Change{Actions: []terraform.ActionType{terraform.NoOpAction}, Before: values, After: values},
+		})
+	}
+	return topics
+}
+
+// Find the owner group with the given ID and the external user IDs of its members in the current Terraform state
+func findOwnerGroupInState(groupID string, plan *terraform.Plan) *OwnerGroup {
+	ownerGroup := &OwnerGroup{ID: groupID, EligibleApprovers: []string{}}
+	if plan == nil {
+		return ownerGroup
+	}
+
+	ownerGroup.Name = findUserGroupNameInState(groupID, plan)
+	userIDs := []string{}
+	for _, resource := range plan.PriorStateResources() {
+		if resource.Type == terraform.AivenOrganizationUserGroupMember &&
+			resource.Values.GroupID != nil && *resource.Values.GroupID == groupID && resource.Values.UserID != nil {
+			userIDs = append(userIDs, *resource.Values.UserID)
+		}
+	}
+	ownerGroup.EligibleApprovers = findExternalUserIDs(userIDs, plan)
+	return ownerGroup
+}
+
+// Find the name of the user group with the given ID in the current Terraform state
+func findUserGroupNameInState(groupID string, plan *terraform.Plan) string {
+	for _, resource := range plan.PriorStateResources() {
+		if resource.Type == terraform.AivenOrganizationUserGroup &&
+			resource.Values.GroupID != nil && *resource.Values.GroupID == groupID {
+			return resource.Values.Name
+		}
+	}
+	return ""
+}
+
+// Find the owner group of the resource and the external user IDs of its members in the proposed / planned
+// Terraform configuration, nil if the owner group can not be resolved
+func findOwnerGroupInConfig(address string, plan *terraform.Plan) *OwnerGroup {
+	owner, err := findOwnerAddressFromConfig(address, plan)
+	if err != nil {
+		return nil
+	}
+
+	ownerGroup := &OwnerGroup{EligibleApprovers: []string{}}
+	if owner.Kind == terraform.ConstantReference {
+		ownerGroup.ID, ownerGroup.Name = owner.Value, findUserGroupNameInState(owner.Value, plan)
+	} else {
+		ownerGroup.ID, ownerGroup.Name = findUserGroupInPlan(owner.Address, plan)
+	}
+	ownerGroup.EligibleApprovers = findExternalUserIDs(findMemberUserIDsInConfig(owner, plan), plan)
+	return ownerGroup
+}
+
+// Find the ID and name of the user group resource with the given address, the group may exist already
+// or be created by the plan in which case its ID is not known yet
+func findUserGroupInPlan(address string, plan *terraform.Plan) (string, string) {
+	var groupID, name string
+	for _, resource := range plan.PriorStateResources() {
+		if resource.Address == address && resource.Values.GroupID != nil {
+			groupID, name = *resource.Values.GroupID, resource.Values.Name
+		}
+	}
+	for _, resourceChange := range plan.ResourceChanges {
+		after := resourceChange.Change.After
+		if resourceChange.Address == address && after != nil && after.Name != nil {
+			name = *after.Name
+		}
+	}
+	return groupID, name
+}
+
+// Find the user IDs of the members of the owner group in the proposed / planned Terraform configuration
+func findMemberUserIDsInConfig(owner *terraform.Reference, plan *terraform.Plan) []string {
+	userIDs := []string{}
+	for _, resource := range plan.ConfigurationResources() {
+		if resource.Type != terraform.AivenOrganizationUserGroupMember {
+			continue
+		}
+		scope := terraform.Scope{Module: resource.Module}
+		groupReference, groupErr := plan.ResolveExpression(scope, resource.Expressions.GroupID)
+		memberReference, memberErr := plan.ResolveExpression(scope, resource.Expressions.UserID)
+		if groupErr != nil || memberErr != nil || *groupReference != *owner {
+			continue
+		}
+		if memberReference.Kind == terraform.ConstantReference {
+			userIDs = append(userIDs, memberReference.Value)
+			continue
+		}
+		// members usually refer to the user ID of an organization user data source
+		for _, user := range plan.PriorStateResources() {
+			if user.Address == memberReference.Address && user.Values.UserID != nil {
+				userIDs = append(userIDs, *user.Values.UserID)
+			}
+		}
+	}
+	return userIDs
+}
+
+// Find the external user IDs of all the external identities of the user, none if the user is not known
+func findUserLogins(user *terraform.PriorStateResource, plan *terraform.Plan) []string {
+	if user == nil || user.Values.InternalUserID == "" {
+		return nil
+	}
+	return findExternalUserIDs([]string{user.Values.InternalUserID}, plan)
+}
+
+// Find the external user IDs of the external identities of the given internal user IDs, sorted and without duplicates
+func findExternalUserIDs(userIDs []string, plan *terraform.Plan) []string {
+	externalUserIDs := []string{}
+	for _, resource := range plan.PriorStateResources() {
+		if resource.Type == terraform.AivenExternalIdentity && resource.Values.ExternalUserID != "" &&
+			slices.Contains(userIDs, resource.Values.InternalUserID) {
+			externalUserIDs = append(externalUserIDs, resource.Values.ExternalUserID)
+		}
+	}
+	slices.Sort(externalUserIDs)
+	return slices.Compact(externalUserIDs)
+}
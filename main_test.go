@@ -1,14 +1,21 @@
 package main
 
 import (
+	"aiven/terraform/governance/compliance/checker/internal/directory"
+	"aiven/terraform/governance/compliance/checker/internal/identity"
 	"aiven/terraform/governance/compliance/checker/internal/terraform"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"reflect"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 )
 
 type TestCase struct {
@@ -22,6 +29,22 @@ type Args struct {
 	Requester string
 	Approvers string
 	Plan      string
+	Policy    string
+	FailOn    string
+	Format    string
+	ConfigDir string
+	ReportAll bool
+	ExitCode  bool
+	// GitHubEvent and GitHubReviews are paths to GitHub event payload and reviews files
+	GitHubEvent   string
+	GitHubReviews string
+	// RejectStaleApprovals only counts the approvals of the GitHub reviews for the head of the GitHub event
+	RejectStaleApprovals bool
+	Identities           string
+	Directory            string
+	Strict               bool
+	// Stdout is a file the report is written to instead of being captured
+	Stdout string
 }
 
 func TestE2E_Args(t *testing.T) {
@@ -89,6 +112,7 @@ func TestE2E_PlanWithKnownOwnerUserGroupID(t *testing.T) {
 	}
 
 	plan := "./testdata/plan_with_known_owner_user_group_id.json"
+	fooGroup := &OwnerGroup{ID: "ug4e3b20cee48", Name: "foo", EligibleApprovers: []string{"alice", "bob"}}
 
 	tests := []TestCase{
 		{
@@ -100,6 +124,10 @@ func TestE2E_PlanWithKnownOwnerUserGroupID(t *testing.T) {
 			},
 			ExpectStdout: Result{
 				Ok: false,
+				Diagnostics: &Diagnostics{UnresolvedIdentities: []UnresolvedIdentity{
+					{User: "nonexistent_user", Role: RoleRequester},
+					{User: "charlie", Role: RoleApprover},
+				}},
 				Errors: []ResultError{
 					newRequestError("aiven_kafka_topic.bar[2]", &[]terraform.Tag{}),
 					newRequestError("aiven_kafka_topic.foo", &[]terraform.Tag{}),
@@ -116,6 +144,10 @@ func TestE2E_PlanWithKnownOwnerUserGroupID(t *testing.T) {
 			},
 			ExpectStdout: Result{
 				Ok: false,
+				Diagnostics: &Diagnostics{
+					UnresolvedIdentities: []UnresolvedIdentity{{User: "charlie", Role: RoleRequester}},
+					IgnoredSelfApprovals: []string{"charlie"},
+				},
 				Errors: []ResultError{
 					newRequestError("aiven_kafka_topic.bar[2]", &[]terraform.Tag{}),
 					newRequestError("aiven_kafka_topic.foo", &[]terraform.Tag{}),
@@ -130,6 +162,225 @@ func TestE2E_PlanWithKnownOwnerUserGroupID(t *testing.T) {
 				Approvers: "bob,charlie",
 				Plan:      plan,
 			},
+			ExpectStdout: Result{
+				Ok:          true,
+				Diagnostics: &Diagnostics{UnresolvedIdentities: []UnresolvedIdentity{{User: "charlie", Role: RoleApprover}}},
+				Errors:      []ResultError{},
+			}.toJSON(),
+			ExpectStderr: "",
+		},
+		{
+			Name: fmt.Sprintf("[%s] Reports error if approval is missing from a member of the owner user group", plan),
+			Args: Args{
+				Requester: "alice",
+				Approvers: "frank",
+				Plan:      plan,
+			},
+			ExpectStdout: Result{
+				Ok:          false,
+				Diagnostics: &Diagnostics{UnresolvedIdentities: []UnresolvedIdentity{{User: "frank", Role: RoleApprover}}},
+				Errors: []ResultError{
+					newGovernanceAccessApproveError("aiven_governance_access.foo", "aiven_kafka_topic.foo").
+						withOwnerGroup(fooGroup.without("alice")),
+					newApproveError("aiven_kafka_topic.bar[2]", &[]terraform.Tag{}).withOwnerGroup(fooGroup.without("alice")),
+					newApproveError("aiven_kafka_topic.foo", &[]terraform.Tag{}).withOwnerGroup(fooGroup.without("alice")),
+				},
+			}.toJSON(),
+			ExpectStderr: "",
+		},
+		{
+			Name: fmt.Sprintf("[%s] Does not consider requester as approver", plan),
+			Args: Args{
+				Requester: "alice",
+				Approvers: "alice",
+				Plan:      plan,
+			},
+			ExpectStdout: Result{
+				Ok:          false,
+				Diagnostics: &Diagnostics{IgnoredSelfApprovals: []string{"alice"}},
+				Errors: []ResultError{
+					newGovernanceAccessApproveError("aiven_governance_access.foo", "aiven_kafka_topic.foo").
+						withOwnerGroup(fooGroup.without("alice")),
+					newApproveError("aiven_kafka_topic.bar[2]", &[]terraform.Tag{}).withOwnerGroup(fooGroup.without("alice")),
+					newApproveError("aiven_kafka_topic.foo", &[]terraform.Tag{}).withOwnerGroup(fooGroup.without("alice")),
+				},
+			}.toJSON(),
+			ExpectStderr: "",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			stdout, stderr, runErr := runCommand(dir, test.Args)
+			if test.ExpectStderr != "" {
+				if runErr == nil {
+					t.Fatalf("Expected an error but got none")
+				}
+			} else {
+				if runErr != nil {
+					t.Fatalf("Command execution failed: %v", runErr)
+				}
+			}
+
+			assertOutput(t, "stdout", stdout, test.ExpectStdout)
+			assertOutput(t, "stderr", stderr, test.ExpectStderr)
+		})
+	}
+}
+
+func TestE2E_PlanWithUnknownOwnerUserGroupID(t *testing.T) {
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+
+	plan := "./testdata/plan_with_unknown_owner_user_group_id.json"
+	// the owner group is created by the plan, its ID is not known yet
+	fooGroup := &OwnerGroup{ID: "", Name: "foo", EligibleApprovers: []string{"alice", "bob"}}
+
+	tests := []TestCase{
+		{
+			Name: fmt.Sprintf("[%s] Reports error if requester identity can not be resolved", plan),
+			Args: Args{
+				Requester: "nonexistent_user",
+				Approvers: "bob,charlie",
+				Plan:      plan,
+			},
+			ExpectStdout: Result{
+				Ok: false,
+				Diagnostics: &Diagnostics{UnresolvedIdentities: []UnresolvedIdentity{
+					{User: "nonexistent_user", Role: RoleRequester},
+					{User: "charlie", Role: RoleApprover},
+				}},
+				Errors: []ResultError{
+					newRequestError("aiven_kafka_topic.foo", &[]terraform.Tag{}),
+				},
+			}.toJSON(),
+			ExpectStderr: "",
+		},
+		{
+			Name: fmt.Sprintf("[%s] Reports error if requester is not a member of the owner user group", plan),
+			Args: Args{
+				Requester: "charlie",
+				Approvers: "bob,charlie",
+				Plan:      plan,
+			},
+			ExpectStdout: Result{
+				Ok: false,
+				Diagnostics: &Diagnostics{
+					UnresolvedIdentities: []UnresolvedIdentity{{User: "charlie", Role: RoleRequester}},
+					IgnoredSelfApprovals: []string{"charlie"},
+				},
+				Errors: []ResultError{
+					newRequestError("aiven_kafka_topic.foo", &[]terraform.Tag{}),
+				},
+			}.toJSON(),
+			ExpectStderr: "",
+		},
+		{
+			Name: fmt.Sprintf("[%s] Does not report error if requester is a member of the owner user group", plan),
+			Args: Args{
+				Requester: "alice",
+				Approvers: "bob,charlie",
+				Plan:      plan,
+			},
+			ExpectStdout: Result{
+				Ok:          true,
+				Diagnostics: &Diagnostics{UnresolvedIdentities: []UnresolvedIdentity{{User: "charlie", Role: RoleApprover}}},
+				Errors:      []ResultError{},
+			}.toJSON(),
+			ExpectStderr: "",
+		},
+		{
+			Name: fmt.Sprintf("[%s] Reports error if approval is missing from a member of the owner user group", plan),
+			Args: Args{
+				Requester: "alice",
+				Approvers: "frank",
+				Plan:      plan,
+			},
+			ExpectStdout: Result{
+				Ok:          false,
+				Diagnostics: &Diagnostics{UnresolvedIdentities: []UnresolvedIdentity{{User: "frank", Role: RoleApprover}}},
+				Errors: []ResultError{
+					newGovernanceAccessApproveError("aiven_governance_access.foo", "aiven_kafka_topic.foo").
+						withOwnerGroup(fooGroup.without("alice")),
+					newApproveError("aiven_kafka_topic.foo", &[]terraform.Tag{}).withOwnerGroup(fooGroup.without("alice")),
+				},
+			}.toJSON(),
+			ExpectStderr: "",
+		},
+		{
+			Name: fmt.Sprintf("[%s] Does not consider requester as approver", plan),
+			Args: Args{
+				Requester: "alice",
+				Approvers: "alice",
+				Plan:      plan,
+			},
+			ExpectStdout: Result{
+				Ok:          false,
+				Diagnostics: &Diagnostics{IgnoredSelfApprovals: []string{"alice"}},
+				Errors: []ResultError{
+					newGovernanceAccessApproveError("aiven_governance_access.foo", "aiven_kafka_topic.foo").
+						withOwnerGroup(fooGroup.without("alice")),
+					newApproveError("aiven_kafka_topic.foo", &[]terraform.Tag{}).withOwnerGroup(fooGroup.without("alice")),
+				},
+			}.toJSON(),
+			ExpectStderr: "",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			stdout, stderr, runErr := runCommand(dir, test.Args)
+			if test.ExpectStderr != "" {
+				if runErr == nil {
+					t.Fatalf("Expected an error but got none")
+				}
+			} else {
+				if runErr != nil {
+					t.Fatalf("Command execution failed: %v", runErr)
+				}
+			}
+
+			assertOutput(t, "stdout", stdout, test.ExpectStdout)
+			assertOutput(t, "stderr", stderr, test.ExpectStderr)
+		})
+	}
+}
+
+func TestE2E_PlanWithModules(t *testing.T) {
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+
+	plan := "./testdata/plan_with_modules.json"
+
+	tests := []TestCase{
+		{
+			Name: fmt.Sprintf("[%s] Reports error if requester is not a member of the owner user group", plan),
+			Args: Args{
+				Requester: "charlie",
+				Approvers: "bob",
+				Plan:      plan,
+			},
+			ExpectStdout: Result{
+				Ok:          false,
+				Diagnostics: &Diagnostics{UnresolvedIdentities: []UnresolvedIdentity{{User: "charlie", Role: RoleRequester}}},
+				Errors: []ResultError{
+					newRequestError("module.payments.aiven_kafka_topic.orders", &[]terraform.Tag{{Key: "team", Value: "payments"}}),
+					newRequestError("module.payments.aiven_kafka_topic.refunds", &[]terraform.Tag{}),
+				},
+			}.toJSON(),
+			ExpectStderr: "",
+		},
+		{
+			Name: fmt.Sprintf("[%s] Does not report error if requester and approver are members of the owner user groups", plan),
+			Args: Args{
+				Requester: "alice",
+				Approvers: "bob",
+				Plan:      plan,
+			},
 			ExpectStdout: Result{
 				Ok:     true,
 				Errors: []ResultError{},
@@ -137,42 +388,1267 @@ func TestE2E_PlanWithKnownOwnerUserGroupID(t *testing.T) {
 			ExpectStderr: "",
 		},
 		{
-			Name: fmt.Sprintf("[%s] Reports error if approval is missing from a member of the owner user group", plan),
+			Name: fmt.Sprintf("[%s] Reports error if approval is missing from a member of the owner user group", plan),
+			Args: Args{
+				Requester: "alice",
+				Approvers: "frank",
+				Plan:      plan,
+			},
+			ExpectStdout: Result{
+				Ok:          false,
+				Diagnostics: &Diagnostics{UnresolvedIdentities: []UnresolvedIdentity{{User: "frank", Role: RoleApprover}}},
+				Errors: []ResultError{
+					newApproveError(
+						"module.payments.aiven_kafka_topic.orders", &[]terraform.Tag{{Key: "team", Value: "payments"}},
+					).withOwnerGroup(&OwnerGroup{ID: "ug4e3b20cee48", Name: "payments", EligibleApprovers: []string{"bob"}}),
+					newApproveError("module.payments.aiven_kafka_topic.refunds", &[]terraform.Tag{}).
+						withOwnerGroup(&OwnerGroup{ID: "", Name: "refunds", EligibleApprovers: []string{"bob"}}),
+				},
+			}.toJSON(),
+			ExpectStderr: "",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			stdout, stderr, runErr := runCommand(dir, test.Args)
+			if test.ExpectStderr != "" {
+				if runErr == nil {
+					t.Fatalf("Expected an error but got none")
+				}
+			} else {
+				if runErr != nil {
+					t.Fatalf("Command execution failed: %v", runErr)
+				}
+			}
+
+			assertOutput(t, "stdout", stdout, test.ExpectStdout)
+			assertOutput(t, "stderr", stderr, test.ExpectStderr)
+		})
+	}
+}
+
+func TestE2E_PlanWithUnresolvableOwnerUserGroupID(t *testing.T) {
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+
+	plan := "./testdata/plan_with_unresolvable_owner_user_group_id.json"
+
+	tests := []TestCase{
+		{
+			Name: fmt.Sprintf("[%s] Reports error if owner user group can not be resolved", plan),
+			Args: Args{
+				Requester: "alice",
+				Approvers: "bob",
+				Plan:      plan,
+			},
+			ExpectStdout: Result{
+				Ok: false,
+				Errors: []ResultError{
+					newUnresolvedOwnerError("aiven_kafka_topic.foo", &[]terraform.Tag{}, &terraform.ReferenceError{
+						Reference: "local.owner_user_group_id",
+						Err:       terraform.ErrUnsupportedReference,
+					}),
+				},
+			}.toJSON(),
+			ExpectStderr: "",
+		},
+		{
+			Name: fmt.Sprintf("[%s] Reports error if approval is missing from a member of the owner user group", plan),
+			Args: Args{
+				Requester: "alice",
+				Approvers: "frank",
+				Plan:      plan,
+			},
+			ExpectStdout: Result{
+				Ok:          false,
+				Diagnostics: &Diagnostics{UnresolvedIdentities: []UnresolvedIdentity{{User: "frank", Role: RoleApprover}}},
+				Errors: []ResultError{
+					newApproveError("aiven_kafka_topic.bar", &[]terraform.Tag{}).
+						withOwnerGroup(&OwnerGroup{ID: "ug4e3b20cee48", Name: "", EligibleApprovers: []string{"bob"}}),
+					newApproveError("aiven_kafka_topic.baz", &[]terraform.Tag{}).
+						withOwnerGroup(&OwnerGroup{ID: "", Name: "payments", EligibleApprovers: []string{"bob"}}),
+					newUnresolvedOwnerError("aiven_kafka_topic.foo", &[]terraform.Tag{}, &terraform.ReferenceError{
+						Reference: "local.owner_user_group_id",
+						Err:       terraform.ErrUnsupportedReference,
+					}),
+				},
+			}.toJSON(),
+			ExpectStderr: "",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			stdout, stderr, runErr := runCommand(dir, test.Args)
+			if test.ExpectStderr != "" {
+				if runErr == nil {
+					t.Fatalf("Expected an error but got none")
+				}
+			} else {
+				if runErr != nil {
+					t.Fatalf("Command execution failed: %v", runErr)
+				}
+			}
+
+			assertOutput(t, "stdout", stdout, test.ExpectStdout)
+			assertOutput(t, "stderr", stderr, test.ExpectStderr)
+		})
+	}
+}
+
+func TestE2E_PlanWithInstanceKeys(t *testing.T) {
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+
+	plan := "./testdata/plan_with_instance_keys.json"
+
+	tests := []TestCase{
+		{
+			Name: fmt.Sprintf("[%s] Reports error if approval is missing from a member of the instance owner group", plan),
+			Args: Args{
+				Requester: "alice",
+				Approvers: "bob",
+				Plan:      plan,
+			},
+			ExpectStdout: Result{
+				Ok: false,
+				Errors: []ResultError{
+					newApproveError(`aiven_kafka_topic.t["orders"]`, &[]terraform.Tag{}).
+						withOwnerGroup(&OwnerGroup{ID: "", Name: "orders", EligibleApprovers: []string{}}),
+				},
+			}.toJSON(),
+			ExpectStderr: "",
+		},
+		{
+			Name: fmt.Sprintf("[%s] Reports error if requester is not a member of the instance owner group", plan),
+			Args: Args{
+				Requester: "bob",
+				Approvers: "alice",
+				Plan:      plan,
+			},
+			ExpectStdout: Result{
+				Ok: false,
+				Errors: []ResultError{
+					newRequestError(`aiven_kafka_topic.t["orders"]`, &[]terraform.Tag{}),
+				},
+			}.toJSON(),
+			ExpectStderr: "",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			stdout, stderr, runErr := runCommand(dir, test.Args)
+			if test.ExpectStderr != "" {
+				if runErr == nil {
+					t.Fatalf("Expected an error but got none")
+				}
+			} else {
+				if runErr != nil {
+					t.Fatalf("Command execution failed: %v", runErr)
+				}
+			}
+
+			assertOutput(t, "stdout", stdout, test.ExpectStdout)
+			assertOutput(t, "stderr", stderr, test.ExpectStderr)
+		})
+	}
+}
+
+func TestE2E_PlanWithReplace(t *testing.T) {
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+
+	plan := "./testdata/plan_with_replace.json"
+	groupA := &OwnerGroup{ID: "ug4e3b20cee48", Name: "a", EligibleApprovers: []string{"alice", "bob"}}
+	groupB := &OwnerGroup{ID: "ug4e3b20db73d", Name: "b", EligibleApprovers: []string{"alice", "dave"}}
+
+	tests := []TestCase{
+		{
+			Name: fmt.Sprintf("[%s] Does not report error if both the old and the new owner approved", plan),
+			Args: Args{
+				Requester: "alice",
+				Approvers: "bob,dave",
+				Plan:      plan,
+			},
+			ExpectStdout: Result{
+				Ok:     true,
+				Errors: []ResultError{},
+			}.toJSON(),
+			ExpectStderr: "",
+		},
+		{
+			Name: fmt.Sprintf("[%s] Reports destructive change if approval is missing from the new owner", plan),
+			Args: Args{
+				Requester: "alice",
+				Approvers: "bob",
+				Plan:      plan,
+			},
+			ExpectStdout: Result{
+				Ok: false,
+				Errors: []ResultError{
+					newDestructiveChangeError("aiven_kafka_topic.foo", &[]terraform.Tag{}).withOwnerGroup(groupB.without("alice")),
+				},
+			}.toJSON(),
+			ExpectStderr: "",
+		},
+		{
+			Name: fmt.Sprintf("[%s] Reports destructive change once per owner group if approvals are missing", plan),
+			Args: Args{
+				Requester: "alice",
+				Approvers: "frank",
+				Plan:      plan,
+			},
+			ExpectStdout: Result{
+				Ok:          false,
+				Diagnostics: &Diagnostics{UnresolvedIdentities: []UnresolvedIdentity{{User: "frank", Role: RoleApprover}}},
+				Errors: []ResultError{
+					newDestructiveChangeError("aiven_kafka_topic.bar", &[]terraform.Tag{}).withOwnerGroup(groupA.without("alice")),
+					// the owner changes from a to b, both approve it
+					newDestructiveChangeError("aiven_kafka_topic.foo", &[]terraform.Tag{}).withOwnerGroup(groupA.without("alice")),
+					newDestructiveChangeError("aiven_kafka_topic.foo", &[]terraform.Tag{}).withOwnerGroup(groupB.without("alice")),
+				},
+			}.toJSON(),
+			ExpectStderr: "",
+		},
+		{
+			Name: fmt.Sprintf("[%s] Reports error if requester is not a member of the new owner group", plan),
+			Args: Args{
+				Requester: "bob",
+				Approvers: "alice,dave",
+				Plan:      plan,
+			},
+			ExpectStdout: Result{
+				Ok: false,
+				Errors: []ResultError{
+					newRequestError("aiven_kafka_topic.foo", &[]terraform.Tag{}),
+				},
+			}.toJSON(),
+			ExpectStderr: "",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			stdout, stderr, runErr := runCommand(dir, test.Args)
+			if test.ExpectStderr != "" {
+				if runErr == nil {
+					t.Fatalf("Expected an error but got none")
+				}
+			} else {
+				if runErr != nil {
+					t.Fatalf("Command execution failed: %v", runErr)
+				}
+			}
+
+			assertOutput(t, "stdout", stdout, test.ExpectStdout)
+			assertOutput(t, "stderr", stderr, test.ExpectStderr)
+		})
+	}
+}
+
+func TestE2E_PlanWithSchemas(t *testing.T) {
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+
+	plan := "./testdata/plan_with_schemas.json"
+	// the schema of the record moves from the subject payments-key to orders-value
+	movedPlan := "./testdata/plan_with_moved_schema.json"
+	groupB := &OwnerGroup{ID: "ug4e3b20db73d", Name: "b", EligibleApprovers: []string{"alice", "dave"}}
+	subjectErrors := []ResultError{
+		newSchemaSubjectTopicError("aiven_kafka_schema.order_record",
+			errors.New("subject com.example.Order is not named <topic>-key or <topic>-value"),
+		),
+		newSchemaSubjectTopicError("aiven_kafka_schema.refunds_value",
+			errors.New("topic refunds of subject refunds-value is not found in the plan or the state"),
+		),
+	}
+
+	tests := []TestCase{
+		{
+			Name: fmt.Sprintf("[%s] Reports error if approval is missing from a member of the topic owner group", plan),
+			Args: Args{
+				Requester: "alice",
+				Approvers: "bob",
+				Plan:      plan,
+			},
+			ExpectStdout: Result{
+				Ok: false,
+				Errors: []ResultError{
+					subjectErrors[0],
+					newApproveError("aiven_kafka_schema.payments_key", nil).withOwnerGroup(groupB.without("alice")),
+					subjectErrors[1],
+				},
+			}.toJSON(),
+			ExpectStderr: "",
+		},
+		{
+			Name: fmt.Sprintf("[%s] Reports error if requester is not a member of the topic owner group", plan),
+			Args: Args{
+				Requester: "bob",
+				Approvers: "alice",
+				Plan:      plan,
+			},
+			ExpectStdout: Result{
+				Ok: false,
+				Errors: []ResultError{
+					subjectErrors[0],
+					newRequestError("aiven_kafka_schema.payments_key", nil),
+					subjectErrors[1],
+				},
+			}.toJSON(),
+			ExpectStderr: "",
+		},
+		{
+			Name: fmt.Sprintf("[%s] Reports error if the owner of the previous subject did not approve", movedPlan),
+			Args: Args{
+				Requester: "alice",
+				Approvers: "bob",
+				Plan:      movedPlan,
+			},
+			ExpectStdout: Result{
+				Ok: false,
+				Errors: []ResultError{
+					newDestructiveChangeError("aiven_kafka_schema.record", nil).withOwnerGroup(groupB.without("alice")),
+				},
+			}.toJSON(),
+			ExpectStderr: "",
+		},
+		{
+			Name: fmt.Sprintf("[%s] Reports error if requester is not a member of the previous owner group", movedPlan),
+			Args: Args{
+				Requester: "bob",
+				Approvers: "alice",
+				Plan:      movedPlan,
+			},
+			ExpectStdout: Result{
+				Ok:     false,
+				Errors: []ResultError{newRequestError("aiven_kafka_schema.record", nil)},
+			}.toJSON(),
+			ExpectStderr: "",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			stdout, stderr, runErr := runCommand(dir, test.Args)
+			if test.ExpectStderr != "" {
+				if runErr == nil {
+					t.Fatalf("Expected an error but got none")
+				}
+			} else {
+				if runErr != nil {
+					t.Fatalf("Command execution failed: %v", runErr)
+				}
+			}
+
+			assertOutput(t, "stdout", stdout, test.ExpectStdout)
+			assertOutput(t, "stderr", stderr, test.ExpectStderr)
+		})
+	}
+}
+
+func TestE2E_PlanWithACLs(t *testing.T) {
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+
+	plan := "./testdata/plan_with_acls.json"
+	groupB := &OwnerGroup{ID: "ug4e3b20db73d", Name: "b", EligibleApprovers: []string{"alice", "dave"}}
+	unboundedError := newUnboundedAccessError("aiven_kafka_acl.all", nil,
+		terraform.AccessData{Project: "testproject-hpo9", ServiceName: "kafka1"},
+		terraform.AccessACL{ResourceName: "*", PatternType: patternTypeWildcard},
+	)
+	notFoundError := newAccessTopicNotFoundError("aiven_kafka_native_acl.shipments", nil,
+		terraform.AccessData{Project: "testproject-hpo9", ServiceName: "kafka1"},
+		terraform.AccessACL{ResourceName: "shipments", PatternType: patternTypeLiteral},
+	)
+	unknownError := newUnknownACLTopicsError("aiven_kafka_acl.dynamic", "testproject-hpo9", "kafka1")
+
+	tests := []TestCase{
+		{
+			Name: fmt.Sprintf("[%s] Reports error for each topic the ACL grants access to without owner approval", plan),
+			Args: Args{
+				Requester: "alice",
+				Approvers: "bob",
+				Plan:      plan,
+			},
+			ExpectStdout: Result{
+				Ok: false,
+				Errors: []ResultError{
+					newACLApproveError("aiven_kafka_acl.all", "aiven_kafka_topic.payments").
+						withOwnerGroup(groupB.without("alice")),
+					newACLApproveError("aiven_kafka_acl.all", "data.aiven_kafka_topic.payments_refunds").
+						withOwnerGroup(groupB.without("alice")),
+					unboundedError,
+					unknownError,
+					newACLApproveError("aiven_kafka_acl.payments", "aiven_kafka_topic.payments").
+						withOwnerGroup(groupB.without("alice")),
+					newACLApproveError("aiven_kafka_acl.payments", "data.aiven_kafka_topic.payments_refunds").
+						withOwnerGroup(groupB.without("alice")),
+					notFoundError,
+				},
+			}.toJSON(),
+			ExpectStderr: "",
+		},
+		{
+			Name: fmt.Sprintf("[%s] Reports error for a wildcard and an unknown topic even if all owners approved", plan),
+			Args: Args{
+				Requester: "alice",
+				Approvers: "bob,dave",
+				Plan:      plan,
+			},
+			ExpectStdout: Result{
+				Ok:     false,
+				Errors: []ResultError{unboundedError, unknownError, notFoundError},
+			}.toJSON(),
+			ExpectStderr: "",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			stdout, stderr, runErr := runCommand(dir, test.Args)
+			if test.ExpectStderr != "" {
+				if runErr == nil {
+					t.Fatalf("Expected an error but got none")
+				}
+			} else {
+				if runErr != nil {
+					t.Fatalf("Command execution failed: %v", runErr)
+				}
+			}
+
+			assertOutput(t, "stdout", stdout, test.ExpectStdout)
+			assertOutput(t, "stderr", stderr, test.ExpectStderr)
+		})
+	}
+}
+
+func TestE2E_PlanWithAccessPatterns(t *testing.T) {
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+
+	plan := "./testdata/plan_with_access_patterns.json"
+	groupB := &OwnerGroup{ID: "ug4e3b20db73d", Name: "b", EligibleApprovers: []string{"alice", "dave"}}
+	unboundedError := newUnboundedAccessError("aiven_governance_access.wildcard", nil,
+		terraform.AccessData{Project: "testproject-hpo9", ServiceName: "kafka1"},
+		terraform.AccessACL{ResourceName: "*", PatternType: "LITERAL", Operation: "Write"},
+	)
+	notFoundError := newAccessTopicNotFoundError("aiven_governance_access.shipments", nil,
+		terraform.AccessData{Project: "testproject-hpo9", ServiceName: "kafka1"},
+		terraform.AccessACL{ResourceName: "shipments", PatternType: "LITERAL"},
+	)
+	unknownError := newUnknownAccessDataError("aiven_governance_access.dynamic", nil)
+
+	tests := []TestCase{
+		{
+			Name: fmt.Sprintf("[%s] Reports error for each topic matching the added ACL patterns without owner approval", plan),
+			Args: Args{
+				Requester: "alice",
+				Approvers: "bob",
+				Plan:      plan,
+			},
+			ExpectStdout: Result{
+				Ok: false,
+				Errors: []ResultError{
+					newGovernanceAccessApproveError("aiven_governance_access.analytics", "aiven_kafka_topic.payments").
+						withOwnerGroup(groupB.without("alice")),
+					unknownError,
+					newGovernanceAccessApproveError("aiven_governance_access.payments", "aiven_kafka_topic.payments").
+						withOwnerGroup(groupB.without("alice")),
+					newGovernanceAccessApproveError("aiven_governance_access.payments",
+						"data.aiven_kafka_topic.payments_refunds").withOwnerGroup(groupB.without("alice")),
+					notFoundError,
+					newGovernanceAccessApproveError("aiven_governance_access.wildcard", "aiven_kafka_topic.payments").
+						withOwnerGroup(groupB.without("alice")),
+					newGovernanceAccessApproveError("aiven_governance_access.wildcard",
+						"data.aiven_kafka_topic.payments_refunds").withOwnerGroup(groupB.without("alice")),
+					unboundedError,
+				},
+			}.toJSON(),
+			ExpectStderr: "",
+		},
+		{
+			Name: fmt.Sprintf("[%s] Reports error for unknown access data and ACLs even if all owners approved", plan),
+			Args: Args{
+				Requester: "alice",
+				Approvers: "bob,dave",
+				Plan:      plan,
+			},
+			ExpectStdout: Result{
+				Ok:     false,
+				Errors: []ResultError{unknownError, notFoundError, unboundedError},
+			}.toJSON(),
+			ExpectStderr: "",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			stdout, stderr, runErr := runCommand(dir, test.Args)
+			if test.ExpectStderr != "" {
+				if runErr == nil {
+					t.Fatalf("Expected an error but got none")
+				}
+			} else {
+				if runErr != nil {
+					t.Fatalf("Command execution failed: %v", runErr)
+				}
+			}
+
+			assertOutput(t, "stdout", stdout, test.ExpectStdout)
+			assertOutput(t, "stderr", stderr, test.ExpectStderr)
+		})
+	}
+}
+
+func TestE2E_Policy(t *testing.T) {
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+
+	plan := "./testdata/plan_with_known_owner_user_group_id.json"
+	fooGroup := &OwnerGroup{ID: "ug4e3b20cee48", Name: "foo", EligibleApprovers: []string{"alice", "bob"}}
+
+	tests := []TestCase{
+		{
+			Name: fmt.Sprintf("[%s] Runs the checks of the matching policy rule", plan),
+			Args: Args{
+				Requester: "alice",
+				Approvers: "frank",
+				Plan:      plan,
+				Policy:    "testdata/policy_sandbox.yaml",
+			},
+			ExpectStdout: Result{
+				Ok:          true,
+				Diagnostics: &Diagnostics{UnresolvedIdentities: []UnresolvedIdentity{{User: "frank", Role: RoleApprover}}},
+				Errors:      []ResultError{},
+				Warnings: []ResultError{
+					newMissingTagsError("aiven_kafka_topic.foo", &[]terraform.Tag{}, []string{"team", "env"}),
+					newMissingTagsError("aiven_kafka_topic.foobar", &[]terraform.Tag{}, []string{"team", "env"}),
+				},
+			}.toJSON(),
+			ExpectStderr: "",
+		},
+		{
+			Name: fmt.Sprintf("[%s] Fails on warnings if the threshold is lowered", plan),
+			Args: Args{
+				Requester: "alice",
+				Approvers: "frank",
+				Plan:      plan,
+				Policy:    "testdata/policy_sandbox.yaml",
+				FailOn:    "warning",
+			},
+			ExpectStdout: Result{
+				Ok:          false,
+				Diagnostics: &Diagnostics{UnresolvedIdentities: []UnresolvedIdentity{{User: "frank", Role: RoleApprover}}},
+				Errors: []ResultError{
+					newMissingTagsError("aiven_kafka_topic.foo", &[]terraform.Tag{}, []string{"team", "env"}),
+					newMissingTagsError("aiven_kafka_topic.foobar", &[]terraform.Tag{}, []string{"team", "env"}),
+				},
+				Warnings: []ResultError{},
+			}.toJSON(),
+			ExpectStderr: "",
+		},
+		{
+			Name: fmt.Sprintf("[%s] Policy can override the severity of a check", plan),
+			Args: Args{
+				Requester: "alice",
+				Approvers: "frank",
+				Plan:      plan,
+				Policy:    "testdata/policy_approvals_as_warnings.yaml",
+			},
+			ExpectStdout: Result{
+				Ok:          true,
+				Diagnostics: &Diagnostics{UnresolvedIdentities: []UnresolvedIdentity{{User: "frank", Role: RoleApprover}}},
+				Errors:      []ResultError{},
+				Warnings: []ResultError{
+					withSeverity(newApproveError("aiven_kafka_topic.bar[2]", &[]terraform.Tag{}), SeverityWarning).
+						withOwnerGroup(fooGroup.without("alice")),
+					withSeverity(newApproveError("aiven_kafka_topic.foo", &[]terraform.Tag{}), SeverityWarning).
+						withOwnerGroup(fooGroup.without("alice")),
+				},
+			}.toJSON(),
+			ExpectStderr: "",
+		},
+		{
+			Name: "[./testdata/plan_with_moved_topic.json] Runs the checks of the rule matching the topic before the move",
+			Args: Args{
+				Requester: "alice",
+				Approvers: "frank",
+				Plan:      "./testdata/plan_with_moved_topic.json",
+				Policy:    "testdata/policy_sandbox.yaml",
+			},
+			ExpectStdout: Result{
+				Ok:          false,
+				Diagnostics: &Diagnostics{UnresolvedIdentities: []UnresolvedIdentity{{User: "frank", Role: RoleApprover}}},
+				Errors: []ResultError{
+					newDestructiveChangeError("aiven_kafka_topic.foo", &[]terraform.Tag{}).withOwnerGroup(fooGroup.without("alice")),
+				},
+				Warnings: []ResultError{
+					newMissingTagsError("aiven_kafka_topic.foo", &[]terraform.Tag{}, []string{"team", "env"}),
+				},
+			}.toJSON(),
+			ExpectStderr: "",
+		},
+		{
+			Name: fmt.Sprintf("[%s] Fail-on threshold needs to be a severity", plan),
+			Args: Args{
+				Requester: "alice",
+				Approvers: "bob",
+				Plan:      plan,
+				FailOn:    "critical",
+			},
+			ExpectStdout: "",
+			ExpectStderr: "invalid fail-on threshold: unknown severity \"critical\"\nexit status 1",
+		},
+		{
+			Name: fmt.Sprintf("[%s] Policy file needs to exist", plan),
+			Args: Args{
+				Requester: "alice",
+				Approvers: "bob",
+				Plan:      plan,
+				Policy:    "testdata/nonexistent_policy.yaml",
+			},
+			ExpectStdout: "",
+			ExpectStderr: "invalid policy file\nexit status 1",
+		},
+		{
+			Name: fmt.Sprintf("[%s] Policy can only refer to known checks", plan),
+			Args: Args{
+				Requester: "alice",
+				Approvers: "bob",
+				Plan:      plan,
+				Policy:    "testdata/policy_unknown_check.json",
+			},
+			ExpectStdout: "",
+			ExpectStderr: "invalid policy: rule 1: unknown check \"change_is_approved_by_everyone\"\nexit status 1",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			stdout, stderr, runErr := runCommand(dir, test.Args)
+			if test.ExpectStderr != "" {
+				if runErr == nil {
+					t.Fatalf("Expected an error but got none")
+				}
+			} else {
+				if runErr != nil {
+					t.Fatalf("Command execution failed: %v", runErr)
+				}
+			}
+
+			assertOutput(t, "stdout", stdout, test.ExpectStdout)
+			assertOutput(t, "stderr", stderr, test.ExpectStderr)
+		})
+	}
+}
+
+func TestE2E_Format(t *testing.T) {
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+
+	plan := "./testdata/plan_with_modules.json"
+	configDir := "./testdata/config/plan_with_modules"
+	result := Result{
+		Ok:          false,
+		Diagnostics: &Diagnostics{UnresolvedIdentities: []UnresolvedIdentity{{User: "frank", Role: RoleApprover}}},
+		Errors: []ResultError{
+			newApproveError(
+				"module.payments.aiven_kafka_topic.orders", &[]terraform.Tag{{Key: "team", Value: "payments"}},
+			).withOwnerGroup(&OwnerGroup{ID: "ug4e3b20cee48", Name: "payments", EligibleApprovers: []string{"bob"}}),
+			newApproveError("module.payments.aiven_kafka_topic.refunds", &[]terraform.Tag{}).
+				withOwnerGroup(&OwnerGroup{ID: "", Name: "refunds", EligibleApprovers: []string{"bob"}}),
+		},
+	}
+
+	tests := []TestCase{
+		{
+			Name: fmt.Sprintf("[%s] Reports the result in SARIF located at the resource blocks", plan),
+			Args: Args{
+				Requester: "alice",
+				Approvers: "frank",
+				Plan:      plan,
+				Format:    "sarif",
+				ConfigDir: configDir,
+			},
+			ExpectStdout: formatSARIF(result, FormatContext{
+				PlanPath: filepath.Join(dir, plan),
+				Sources:  terraform.NewSources(filepath.Join(dir, configDir), getTestPlan(t, plan)),
+			}),
+			ExpectStderr: "",
+		},
+		{
+			Name: fmt.Sprintf("[%s] Reports the result in SARIF located at the plan without configuration", plan),
+			Args: Args{
+				Requester: "alice",
+				Approvers: "frank",
+				Plan:      plan,
+				Format:    "sarif",
+			},
+			ExpectStdout: formatSARIF(result, FormatContext{PlanPath: filepath.Join(dir, plan)}),
+			ExpectStderr: "",
+		},
+		{
+			Name: fmt.Sprintf("[%s] Reports the result in markdown", plan),
+			Args: Args{
+				Requester: "alice",
+				Approvers: "frank",
+				Plan:      plan,
+				Format:    "markdown",
+			},
+			ExpectStdout: formatMarkdown(withActions(result, terraform.UpdateChange, terraform.CreateChange), FormatContext{}),
+			ExpectStderr: "",
+		},
+		{
+			Name: fmt.Sprintf("[%s] Reports a JUnit test case per resource and check", plan),
+			Args: Args{
+				Requester: "alice",
+				Approvers: "frank",
+				Plan:      plan,
+				Format:    "junit",
+			},
+			ExpectStdout: formatJUnit(Result{Evaluations: []CheckEvaluation{
+				evaluation("module.payments.aiven_kafka_topic.orders", "change_is_requested_by_owner", OutcomePassed),
+				evaluation("module.payments.aiven_kafka_topic.orders", "change_is_approved_by_owner", OutcomeFailed,
+					withActions(result, terraform.UpdateChange).Errors[0]),
+				evaluation("module.payments.aiven_kafka_topic.refunds", "change_is_requested_by_owner", OutcomePassed),
+				evaluation("module.payments.aiven_kafka_topic.refunds", "change_is_approved_by_owner", OutcomeFailed,
+					withActions(result, terraform.UpdateChange, terraform.CreateChange).Errors[1]),
+				evaluation("module.team.aiven_organization_user_group.new", "", OutcomeSkipped),
+				evaluation("module.team.aiven_organization_user_group_member.new_alice", "", OutcomeSkipped),
+				evaluation("module.team.aiven_organization_user_group_member.new_bob", "", OutcomeSkipped),
+			}}, FormatContext{}),
+			ExpectStderr: "",
+		},
+		{
+			Name: fmt.Sprintf("[%s] Format needs to be known", plan),
+			Args: Args{
+				Requester: "alice",
+				Approvers: "frank",
+				Plan:      plan,
+				Format:    "xml",
+			},
+			ExpectStdout: "",
+			ExpectStderr: "unknown format \"xml\"\nexit status 1",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			stdout, stderr, runErr := runCommand(dir, test.Args)
+			if test.ExpectStderr != "" {
+				if runErr == nil {
+					t.Fatalf("Expected an error but got none")
+				}
+			} else {
+				if runErr != nil {
+					t.Fatalf("Command execution failed: %v", runErr)
+				}
+			}
+
+			assertOutput(t, "stdout", stdout, test.ExpectStdout)
+			assertOutput(t, "stderr", stderr, test.ExpectStderr)
+		})
+	}
+}
+
+func TestE2E_FormatGitHub(t *testing.T) {
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+
+	plan := "./testdata/plan_with_modules.json"
+	result := Result{
+		Ok:          false,
+		Diagnostics: &Diagnostics{UnresolvedIdentities: []UnresolvedIdentity{{User: "frank", Role: RoleApprover}}},
+		Errors: withActions(Result{Errors: []ResultError{
+			newApproveError(
+				"module.payments.aiven_kafka_topic.orders", &[]terraform.Tag{{Key: "team", Value: "payments"}},
+			).withOwnerGroup(&OwnerGroup{ID: "ug4e3b20cee48", Name: "payments", EligibleApprovers: []string{"bob"}}),
+			newApproveError("module.payments.aiven_kafka_topic.refunds", &[]terraform.Tag{}).
+				withOwnerGroup(&OwnerGroup{ID: "", Name: "refunds", EligibleApprovers: []string{"bob"}}),
+		}}, terraform.UpdateChange, terraform.CreateChange).Errors,
+	}
+
+	t.Run(fmt.Sprintf("[%s] Annotates the errors and writes the summary and output files", plan), func(t *testing.T) {
+		summary, output := filepath.Join(t.TempDir(), "summary.md"), filepath.Join(t.TempDir(), "output")
+		t.Setenv("GITHUB_STEP_SUMMARY", summary)
+		t.Setenv("GITHUB_OUTPUT", output)
+
+		stdout, _, runErr := runCommand(dir, Args{Requester: "alice", Approvers: "frank", Plan: plan, Format: "github"})
+		if runErr != nil {
+			t.Fatalf("Command execution failed: %v", runErr)
+		}
+		assertOutput(t, "stdout", stdout, formatGitHub(result, FormatContext{PlanPath: filepath.Join(dir, plan)}))
+
+		summaryContent, _ := os.ReadFile(summary)
+		assertOutput(t, "summary", string(summaryContent), formatMarkdown(result, FormatContext{}))
+		outputContent, _ := os.ReadFile(output)
+		assertOutput(t, "output", string(outputContent), "result="+result.toJSON())
+	})
+
+	t.Run(fmt.Sprintf("[%s] Only annotates the errors outside of GitHub Actions", plan), func(t *testing.T) {
+		t.Setenv("GITHUB_STEP_SUMMARY", "")
+		t.Setenv("GITHUB_OUTPUT", "")
+
+		stdout, _, runErr := runCommand(dir, Args{Requester: "alice", Approvers: "frank", Plan: plan, Format: "github"})
+		if runErr != nil {
+			t.Fatalf("Command execution failed: %v", runErr)
+		}
+		assertOutput(t, "stdout", stdout, formatGitHub(result, FormatContext{PlanPath: filepath.Join(dir, plan)}))
+	})
+}
+
+func TestE2E_GitHubEvent(t *testing.T) {
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+
+	plan := "./testdata/plan_with_known_owner_user_group_id.json"
+	fooGroup := &OwnerGroup{ID: "ug4e3b20cee48", Name: "foo", EligibleApprovers: []string{"bob"}}
+	staleApproval := StaleApproval{
+		Approver: findExternalIdentity("bob", getTestPlan(t, plan)),
+		CommitID: "abc1234f5e6d7c8b9a0f1e2d3c4b5a6978877665",
+	}
+	unresolvedCharlie := &Diagnostics{UnresolvedIdentities: []UnresolvedIdentity{{User: "charlie", Role: RoleApprover}}}
+
+	tests := []TestCase{
+		{
+			Name: fmt.Sprintf("[%s] Derives the requester and approvers from the event and reviews", plan),
+			Args: Args{
+				Plan:          plan,
+				GitHubEvent:   "testdata/github/pull_request_event.json",
+				GitHubReviews: "testdata/github/reviews.json",
+			},
+			ExpectStdout: Result{Ok: true}.toJSON(),
+			ExpectStderr: "",
+		},
+		{
+			Name: fmt.Sprintf("[%s] Reports error if a later review requests changes", plan),
+			Args: Args{
+				Plan:          plan,
+				GitHubEvent:   "testdata/github/pull_request_event.json",
+				GitHubReviews: "testdata/github/reviews_changes_requested.json",
+			},
+			ExpectStdout: Result{
+				Ok: false,
+				Errors: []ResultError{
+					newGovernanceAccessApproveError("aiven_governance_access.foo", "aiven_kafka_topic.foo").withOwnerGroup(fooGroup),
+					newApproveError("aiven_kafka_topic.bar[2]", &[]terraform.Tag{}).withOwnerGroup(fooGroup),
+					newApproveError("aiven_kafka_topic.foo", &[]terraform.Tag{}).withOwnerGroup(fooGroup),
+				},
+			}.toJSON(),
+			ExpectStderr: "",
+		},
+		{
+			Name: fmt.Sprintf("[%s] Counts stale approvals unless they are rejected", plan),
+			Args: Args{
+				Plan:          plan,
+				GitHubEvent:   "testdata/github/pull_request_event.json",
+				GitHubReviews: "testdata/github/reviews_stale.json",
+			},
+			ExpectStdout: Result{Ok: true, Diagnostics: unresolvedCharlie}.toJSON(),
+			ExpectStderr: "",
+		},
+		{
+			Name: fmt.Sprintf("[%s] Reports error if the approval of a member is stale", plan),
+			Args: Args{
+				Plan:                 plan,
+				GitHubEvent:          "testdata/github/pull_request_event.json",
+				GitHubReviews:        "testdata/github/reviews_stale.json",
+				RejectStaleApprovals: true,
+			},
+			ExpectStdout: Result{
+				Ok:          false,
+				Diagnostics: unresolvedCharlie,
+				Errors: []ResultError{
+					newGovernanceAccessApproveError("aiven_governance_access.foo", "aiven_kafka_topic.foo").withOwnerGroup(fooGroup),
+					newStaleApprovalError("aiven_governance_access.foo", nil, staleApproval),
+					newApproveError("aiven_kafka_topic.bar[2]", &[]terraform.Tag{}).withOwnerGroup(fooGroup),
+					newStaleApprovalError("aiven_kafka_topic.bar[2]", &[]terraform.Tag{}, staleApproval),
+					newApproveError("aiven_kafka_topic.foo", &[]terraform.Tag{}).withOwnerGroup(fooGroup),
+					newStaleApprovalError("aiven_kafka_topic.foo", &[]terraform.Tag{}, staleApproval),
+				},
+			}.toJSON(),
+			ExpectStderr: "",
+		},
+		{
+			Name: fmt.Sprintf("[%s] Event needs to be a pull request event", plan),
+			Args: Args{
+				Plan:        plan,
+				GitHubEvent: "testdata/github/push_event.json",
+			},
+			ExpectStdout: "",
+			ExpectStderr: "invalid GitHub event file: not a pull request event\nexit status 1",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			stdout, stderr, runErr := runCommand(dir, test.Args)
+			if test.ExpectStderr != "" {
+				if runErr == nil {
+					t.Fatalf("Expected an error but got none")
+				}
+			} else {
+				if runErr != nil {
+					t.Fatalf("Command execution failed: %v", runErr)
+				}
+			}
+
+			assertOutput(t, "stdout", stdout, test.ExpectStdout)
+			assertOutput(t, "stderr", stderr, test.ExpectStderr)
+		})
+	}
+}
+
+func TestE2E_Identities(t *testing.T) {
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+
+	plan := "./testdata/plan_with_known_owner_user_group_id.json"
+	conflicts := &Diagnostics{IdentityConflicts: []IdentityConflict{
+		{Alias: "bob", InternalUserIDs: []string{"u4e3b0f02414", "u4e3b0f09999"}},
+	}}
+	fooGroup := &OwnerGroup{ID: "ug4e3b20cee48", Name: "foo", EligibleApprovers: []string{"bob"}}
+
+	tests := []TestCase{
+		{
+			Name: fmt.Sprintf("[%s] Reports error if the requester is only known by the identities file", plan),
+			Args: Args{
+				Requester: "alice-gitlab",
+				Approvers: "bob",
+				Plan:      plan,
+			},
+			ExpectStdout: Result{
+				Ok:          false,
+				Diagnostics: &Diagnostics{UnresolvedIdentities: []UnresolvedIdentity{{User: "alice-gitlab", Role: RoleRequester}}},
+				Errors: []ResultError{
+					newRequestError("aiven_kafka_topic.bar[2]", &[]terraform.Tag{}),
+					newRequestError("aiven_kafka_topic.foo", &[]terraform.Tag{}),
+				},
+			}.toJSON(),
+			ExpectStderr: "",
+		},
+		{
+			Name: fmt.Sprintf("[%s] Resolves the requester through the YAML identities file", plan),
+			Args: Args{
+				Requester:  "alice-gitlab",
+				Approvers:  "bob",
+				Plan:       plan,
+				Identities: "testdata/identities/identities.yaml",
+			},
+			ExpectStdout: Result{Ok: true, Diagnostics: conflicts}.toJSON(),
+			ExpectStderr: "",
+		},
+		{
+			Name: fmt.Sprintf("[%s] Resolves the requester by email through the CSV identities file", plan),
+			Args: Args{
+				Requester:  "alice@example.com",
+				Approvers:  "bob",
+				Plan:       plan,
+				Identities: "testdata/identities/identities.csv",
+			},
+			ExpectStdout: Result{Ok: true, Diagnostics: conflicts}.toJSON(),
+			ExpectStderr: "",
+		},
+		{
+			Name: fmt.Sprintf("[%s] Does not consider an alias of the requester as approver", plan),
+			Args: Args{
+				Requester:  "alice",
+				Approvers:  "alice@example.com",
+				Plan:       plan,
+				Identities: "testdata/identities/identities.yaml",
+			},
+			ExpectStdout: Result{
+				Ok: false,
+				Diagnostics: &Diagnostics{
+					IdentityConflicts:    conflicts.IdentityConflicts,
+					IgnoredSelfApprovals: []string{"alice@example.com"},
+				},
+				Errors: []ResultError{
+					newGovernanceAccessApproveError("aiven_governance_access.foo", "aiven_kafka_topic.foo").
+						withOwnerGroup(fooGroup),
+					newApproveError("aiven_kafka_topic.bar[2]", &[]terraform.Tag{}).withOwnerGroup(fooGroup),
+					newApproveError("aiven_kafka_topic.foo", &[]terraform.Tag{}).withOwnerGroup(fooGroup),
+				},
+			}.toJSON(),
+			ExpectStderr: "",
+		},
+		{
+			Name: fmt.Sprintf("[%s] Identities file needs to be valid", plan),
+			Args: Args{
+				Requester:  "alice",
+				Approvers:  "bob",
+				Plan:       plan,
+				Identities: "testdata/identities/identities_invalid_header.csv",
+			},
+			ExpectStdout: "",
+			ExpectStderr: "invalid identities file: the header must be alias,internal_user_id\nexit status 1",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			stdout, stderr, runErr := runCommand(dir, test.Args)
+			if test.ExpectStderr != "" {
+				if runErr == nil {
+					t.Fatalf("Expected an error but got none")
+				}
+			} else {
+				if runErr != nil {
+					t.Fatalf("Command execution failed: %v", runErr)
+				}
+			}
+
+			assertOutput(t, "stdout", stdout, test.ExpectStdout)
+			assertOutput(t, "stderr", stderr, test.ExpectStderr)
+		})
+	}
+}
+
+func TestE2E_Strict(t *testing.T) {
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+
+	plan := "./testdata/plan_with_known_owner_user_group_id.json"
+	unresolvedRequester := Result{
+		Ok: false,
+		Diagnostics: &Diagnostics{
+			UnresolvedIdentities: []UnresolvedIdentity{{User: "nonexistent_user", Role: RoleRequester}},
+		},
+		Errors: []ResultError{
+			newRequestError("aiven_kafka_topic.bar[2]", &[]terraform.Tag{}),
+			newRequestError("aiven_kafka_topic.foo", &[]terraform.Tag{}),
+			newRequesterUnresolvedError("nonexistent_user"),
+		},
+	}.toJSON()
+
+	tests := []TestCase{
+		{
+			Name: fmt.Sprintf("[%s] Reports error if the requester has no external identity", plan),
+			Args: Args{
+				Requester: "nonexistent_user",
+				Approvers: "bob",
+				Plan:      plan,
+				Strict:    true,
+			},
+			ExpectStdout: unresolvedRequester,
+			ExpectStderr: "",
+		},
+		{
+			Name: fmt.Sprintf("[%s] Exits with 2 if the requester has no external identity", plan),
+			Args: Args{
+				Requester: "nonexistent_user",
+				Approvers: "bob",
+				Plan:      plan,
+				Strict:    true,
+				ExitCode:  true,
+			},
+			ExpectStdout: unresolvedRequester,
+			ExpectStderr: "exit status 2",
+		},
+		{
+			Name: fmt.Sprintf("[%s] Only reports unresolved approvers", plan),
+			Args: Args{
+				Requester: "alice",
+				Approvers: "bob,charlie",
+				Plan:      plan,
+				Strict:    true,
+			},
+			ExpectStdout: Result{
+				Ok:          true,
+				Diagnostics: &Diagnostics{UnresolvedIdentities: []UnresolvedIdentity{{User: "charlie", Role: RoleApprover}}},
+			}.toJSON(),
+			ExpectStderr: "",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			stdout, stderr, runErr := runCommand(dir, test.Args)
+			if test.ExpectStderr != "" {
+				if runErr == nil {
+					t.Fatalf("Expected an error but got none")
+				}
+			} else {
+				if runErr != nil {
+					t.Fatalf("Command execution failed: %v", runErr)
+				}
+			}
+
+			assertOutput(t, "stdout", stdout, test.ExpectStdout)
+			assertOutput(t, "stderr", stderr, test.ExpectStderr)
+		})
+	}
+}
+
+func TestE2E_Directory(t *testing.T) {
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+
+	plan := "./testdata/plan_with_known_owner_user_group_id.json"
+	snapshot := "testdata/directory/directory.json"
+	generatedAt := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		Name string
+		Args Args
+	}{
+		{
+			Name: fmt.Sprintf("[%s] Counts the requester as a member of the owner group in the snapshot", plan),
+			Args: Args{Requester: "charlie@example.com", Approvers: "bob", Plan: plan, Directory: snapshot},
+		},
+		{
+			Name: fmt.Sprintf("[%s] Counts the approval of a member of the owner group in the snapshot", plan),
+			Args: Args{Requester: "alice", Approvers: "charlie@example.com", Plan: plan, Directory: snapshot},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			stdout, _, runErr := runCommand(dir, test.Args)
+			if runErr != nil {
+				t.Fatalf("Command execution failed: %v", runErr)
+			}
+
+			// the age of the snapshot depends on when the test runs
+			var result Result
+			if unmarshalErr := json.Unmarshal([]byte(stdout), &result); unmarshalErr != nil {
+				t.Fatalf("Invalid result JSON: %v", unmarshalErr)
+			}
+			if !result.Ok || len(result.Errors) != 0 || result.Diagnostics != nil {
+				t.Errorf("Expected a compliant result without diagnostics, but got: %q", stdout)
+			}
+			if result.Directory == nil || !result.Directory.GeneratedAt.Equal(generatedAt) ||
+				result.Directory.AgeSeconds <= 0 {
+				t.Errorf("Expected the snapshot of %s with its age, but got: %v", generatedAt, result.Directory)
+			}
+		})
+	}
+
+	t.Run(fmt.Sprintf("[%s] Fails if the snapshot is invalid", plan), func(t *testing.T) {
+		_, stderr, runErr := runCommand(dir, Args{
+			Requester: "alice",
+			Approvers: "bob",
+			Plan:      plan,
+			Directory: "testdata/directory/directory_missing_generated_at.json",
+		})
+		if runErr == nil {
+			t.Fatalf("Expected an error but got none")
+		}
+		assertOutput(t, "stderr", stderr, "invalid directory file: generated_at is required\nexit status 1")
+	})
+}
+
+func TestE2E_ReportAll(t *testing.T) {
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+
+	plan := "./testdata/plan_with_modules.json"
+	orders, refunds := "module.payments.aiven_kafka_topic.orders", "module.payments.aiven_kafka_topic.refunds"
+	skipped := []ResourceReport{
+		resourceReport("module.team.aiven_organization_user_group.new", terraform.CreateChange,
+			checkReport("", OutcomeSkipped, nil),
+		),
+		resourceReport("module.team.aiven_organization_user_group_member.new_alice", terraform.CreateChange,
+			checkReport("", OutcomeSkipped, nil),
+		),
+		resourceReport("module.team.aiven_organization_user_group_member.new_bob", terraform.CreateChange,
+			checkReport("", OutcomeSkipped, nil),
+		),
+	}
+	ordersApproveError := newApproveError(orders, &[]terraform.Tag{{Key: "team", Value: "payments"}}).
+		withOwnerGroup(&OwnerGroup{ID: "ug4e3b20cee48", Name: "payments", EligibleApprovers: []string{"bob"}})
+	refundsApproveError := newApproveError(refunds, &[]terraform.Tag{}).
+		withOwnerGroup(&OwnerGroup{ID: "", Name: "refunds", EligibleApprovers: []string{"bob"}})
+
+	tests := []TestCase{
+		{
+			Name: fmt.Sprintf("[%s] Reports the passing checks and the approvers satisfying them", plan),
 			Args: Args{
 				Requester: "alice",
-				Approvers: "frank",
+				Approvers: "bob",
 				Plan:      plan,
+				ReportAll: true,
 			},
 			ExpectStdout: Result{
-				Ok: false,
-				Errors: []ResultError{
-					{
-						Address: "aiven_governance_access.foo",
-						Error:   "approval is required from a owner of aiven_kafka_topic.foo",
-					},
-					newApproveError("aiven_kafka_topic.bar[2]", &[]terraform.Tag{}),
-					newApproveError("aiven_kafka_topic.foo", &[]terraform.Tag{}),
-				},
+				Ok: true,
+				Resources: append([]ResourceReport{
+					resourceReport(orders, terraform.UpdateChange,
+						checkReport("change_is_requested_by_owner", OutcomePassed, nil,
+							Evidence{Requirement: "requester is a member of the owner group ug4e3b20cee48", SatisfiedBy: "alice"},
+						),
+						checkReport("change_is_approved_by_owner", OutcomePassed, nil,
+							Evidence{Requirement: "approval from a member of the owner group ug4e3b20cee48", SatisfiedBy: "bob"},
+						),
+					),
+					resourceReport(refunds, terraform.CreateChange,
+						checkReport("change_is_requested_by_owner", OutcomePassed, nil,
+							Evidence{Requirement: "requester is a member of the owner group of " + refunds, SatisfiedBy: "alice"},
+						),
+						checkReport("change_is_approved_by_owner", OutcomePassed, nil,
+							Evidence{Requirement: "approval from a member of the owner group of " + refunds, SatisfiedBy: "bob"},
+						),
+					),
+				}, skipped...),
 			}.toJSON(),
 			ExpectStderr: "",
 		},
 		{
-			Name: fmt.Sprintf("[%s] Does not consider requester as approver", plan),
+			Name: fmt.Sprintf("[%s] Reports the failing checks next to the passing ones", plan),
 			Args: Args{
 				Requester: "alice",
-				Approvers: "alice",
+				Approvers: "frank",
 				Plan:      plan,
+				ReportAll: true,
 			},
 			ExpectStdout: Result{
-				Ok: false,
-				Errors: []ResultError{
-					{
-						Address: "aiven_governance_access.foo",
-						Error:   "approval is required from a owner of aiven_kafka_topic.foo",
-					},
-					newApproveError("aiven_kafka_topic.bar[2]", &[]terraform.Tag{}),
-					newApproveError("aiven_kafka_topic.foo", &[]terraform.Tag{}),
-				},
+				Ok:          false,
+				Diagnostics: &Diagnostics{UnresolvedIdentities: []UnresolvedIdentity{{User: "frank", Role: RoleApprover}}},
+				Errors:      []ResultError{ordersApproveError, refundsApproveError},
+				Resources: append([]ResourceReport{
+					resourceReport(orders, terraform.UpdateChange,
+						checkReport("change_is_requested_by_owner", OutcomePassed, nil,
+							Evidence{Requirement: "requester is a member of the owner group ug4e3b20cee48", SatisfiedBy: "alice"},
+						),
+						checkReport("change_is_approved_by_owner", OutcomeFailed, []ResultError{ordersApproveError}),
+					),
+					resourceReport(refunds, terraform.CreateChange,
+						checkReport("change_is_requested_by_owner", OutcomePassed, nil,
+							Evidence{Requirement: "requester is a member of the owner group of " + refunds, SatisfiedBy: "alice"},
+						),
+						checkReport("change_is_approved_by_owner", OutcomeFailed, []ResultError{refundsApproveError}),
+					),
+				}, skipped...),
 			}.toJSON(),
 			ExpectStderr: "",
 		},
@@ -197,95 +1673,130 @@ func TestE2E_PlanWithKnownOwnerUserGroupID(t *testing.T) {
 	}
 }
 
-func TestE2E_PlanWithUnknownOwnerUserGroupID(t *testing.T) {
+func TestE2E_ExitCode(t *testing.T) {
 	dir, err := os.Getwd()
 	if err != nil {
 		t.Fatalf("Failed to get working directory: %v", err)
 	}
 
-	plan := "./testdata/plan_with_unknown_owner_user_group_id.json"
+	plan := "./testdata/plan_with_modules.json"
+	payments := &OwnerGroup{ID: "ug4e3b20cee48", Name: "payments", EligibleApprovers: []string{"alice", "bob"}}
+	refunds := &OwnerGroup{ID: "", Name: "refunds", EligibleApprovers: []string{"alice", "bob"}}
+
+	// the GitHub output can't be written to a directory
+	outputDir := t.TempDir()
+	t.Setenv("GITHUB_OUTPUT", outputDir)
+	t.Setenv("GITHUB_STEP_SUMMARY", "")
 
 	tests := []TestCase{
 		{
-			Name: fmt.Sprintf("[%s] Reports error if requester identity can not be resolved", plan),
+			Name: fmt.Sprintf("[%s] Exits with 0 if the change is compliant", plan),
 			Args: Args{
-				Requester: "nonexistent_user",
-				Approvers: "bob,charlie",
+				Requester: "alice",
+				Approvers: "bob",
 				Plan:      plan,
+				ExitCode:  true,
 			},
-			ExpectStdout: Result{
-				Ok: false,
-				Errors: []ResultError{
-					newRequestError("aiven_kafka_topic.foo", &[]terraform.Tag{}),
-				},
-			}.toJSON(),
+			ExpectStdout: Result{Ok: true}.toJSON(),
 			ExpectStderr: "",
 		},
 		{
-			Name: fmt.Sprintf("[%s] Reports error if requester is not a member of the owner user group", plan),
+			Name: fmt.Sprintf("[%s] Exits with 2 if the change is not compliant", plan),
 			Args: Args{
-				Requester: "charlie",
-				Approvers: "bob,charlie",
+				Requester: "alice",
+				Approvers: "frank",
 				Plan:      plan,
+				ExitCode:  true,
 			},
 			ExpectStdout: Result{
-				Ok: false,
+				Ok:          false,
+				Diagnostics: &Diagnostics{UnresolvedIdentities: []UnresolvedIdentity{{User: "frank", Role: RoleApprover}}},
 				Errors: []ResultError{
-					newRequestError("aiven_kafka_topic.foo", &[]terraform.Tag{}),
+					newApproveError("module.payments.aiven_kafka_topic.orders", &[]terraform.Tag{{Key: "team", Value: "payments"}}).
+						withOwnerGroup(payments.without("alice")),
+					newApproveError("module.payments.aiven_kafka_topic.refunds", &[]terraform.Tag{}).
+						withOwnerGroup(refunds.without("alice")),
 				},
 			}.toJSON(),
-			ExpectStderr: "",
+			ExpectStderr: "exit status 2",
 		},
 		{
-			Name: fmt.Sprintf("[%s] Does not report error if requester is a member of the owner user group", plan),
+			Name: fmt.Sprintf("[%s] Exits with 3 if the change only has warnings", plan),
 			Args: Args{
 				Requester: "alice",
-				Approvers: "bob,charlie",
+				Approvers: "frank",
 				Plan:      plan,
+				Policy:    "testdata/policy_approvals_as_warnings.yaml",
+				ExitCode:  true,
 			},
 			ExpectStdout: Result{
-				Ok:     true,
-				Errors: []ResultError{},
+				Ok:          true,
+				Diagnostics: &Diagnostics{UnresolvedIdentities: []UnresolvedIdentity{{User: "frank", Role: RoleApprover}}},
+				Warnings: []ResultError{
+					withSeverity(newApproveError(
+						"module.payments.aiven_kafka_topic.orders", &[]terraform.Tag{{Key: "team", Value: "payments"}},
+					), SeverityWarning).withOwnerGroup(payments.without("alice")),
+					withSeverity(newApproveError("module.payments.aiven_kafka_topic.refunds", &[]terraform.Tag{}),
+						SeverityWarning).withOwnerGroup(refunds.without("alice")),
+				},
 			}.toJSON(),
-			ExpectStderr: "",
+			ExpectStderr: "exit status 3",
 		},
 		{
-			Name: fmt.Sprintf("[%s] Reports error if approval is missing from a member of the owner user group", plan),
+			Name: "Exits with 4 if the input is invalid",
 			Args: Args{
 				Requester: "alice",
-				Approvers: "frank",
+				Approvers: "bob",
+				ExitCode:  true,
+			},
+			ExpectStdout: "",
+			ExpectStderr: "plan is a required argument\nexit status 4",
+		},
+		{
+			Name: "Exits with 4 if the plan is invalid",
+			Args: Args{
+				Requester: "alice",
+				Approvers: "bob",
+				Plan:      "testdata/not_json.py",
+				ExitCode:  true,
+			},
+			ExpectStdout: "",
+			ExpectStderr: "invalid plan JSON file\nexit status 4",
+		},
+		{
+			Name: fmt.Sprintf("[%s] Exits with 5 if the report can't be written", plan),
+			Args: Args{
+				Requester: "alice",
+				Approvers: "bob",
 				Plan:      plan,
+				Format:    "github",
+				ExitCode:  true,
 			},
-			ExpectStdout: Result{
-				Ok: false,
-				Errors: []ResultError{
-					{
-						Address: "aiven_governance_access.foo",
-						Error:   "approval is required from a owner of aiven_kafka_topic.foo",
-					},
-					newApproveError("aiven_kafka_topic.foo", &[]terraform.Tag{}),
-				},
-			}.toJSON(),
-			ExpectStderr: "",
+			ExpectStdout: "",
+			ExpectStderr: fmt.Sprintf("failed to write the step output: open %s: is a directory\nexit status 5", outputDir),
 		},
 		{
-			Name: fmt.Sprintf("[%s] Does not consider requester as approver", plan),
+			Name: fmt.Sprintf("[%s] Exits with 5 if the report can't be written to stdout", plan),
 			Args: Args{
 				Requester: "alice",
-				Approvers: "alice",
+				Approvers: "bob",
 				Plan:      plan,
+				ExitCode:  true,
+				Stdout:    "/dev/full",
 			},
-			ExpectStdout: Result{
-				Ok: false,
-				Errors: []ResultError{
-					{
-						Address: "aiven_governance_access.foo",
-						Error:   "approval is required from a owner of aiven_kafka_topic.foo",
-					},
-					newApproveError("aiven_kafka_topic.foo", &[]terraform.Tag{}),
-				},
-			}.toJSON(),
-			ExpectStderr: "",
+			ExpectStdout: "",
+			ExpectStderr: "failed to write the report: write /dev/stdout: no space left on device\nexit status 5",
+		},
+		{
+			Name: fmt.Sprintf("[%s] Exits with 1 on errors without exit codes", plan),
+			Args: Args{
+				Requester: "alice",
+				Approvers: "bob",
+				Plan:      plan,
+				Format:    "github",
+			},
+			ExpectStdout: "",
+			ExpectStderr: fmt.Sprintf("failed to write the step output: open %s: is a directory\nexit status 1", outputDir),
 		},
 	}
 
@@ -308,6 +1819,44 @@ func TestE2E_PlanWithUnknownOwnerUserGroupID(t *testing.T) {
 	}
 }
 
+func TestE2E_Panic(t *testing.T) {
+	// the checker panics in a subprocess of the test binary, as the exit ends the process
+	if os.Getenv("CHECKER_PANIC") != "" {
+		exit := exiter{logger: log.New(os.Stderr, "", 0), exitCodes: os.Getenv("CHECKER_PANIC") == "exit-code"}
+		defer exit.recover()
+		panic("unexpected plan")
+	}
+
+	tests := []struct {
+		Name         string
+		Env          string
+		ExpectStatus int
+	}{
+		{Name: "Exits with 5 on a panic", Env: "exit-code", ExpectStatus: exitInternalError},
+		{Name: "Exits with 1 on a panic without exit codes", Env: "default", ExpectStatus: 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			var stderrBuffer strings.Builder
+			cmd := exec.Command(os.Args[0], "-test.run=^TestE2E_Panic$")
+			cmd.Env = append(os.Environ(), "CHECKER_PANIC="+test.Env)
+			cmd.Stderr = &stderrBuffer
+
+			var exitErr *exec.ExitError
+			if !errors.As(cmd.Run(), &exitErr) {
+				t.Fatalf("Expected the command to exit with an error")
+			}
+			if exitErr.ExitCode() != test.ExpectStatus {
+				t.Errorf("Expected exit status %d, got %d", test.ExpectStatus, exitErr.ExitCode())
+			}
+			if !strings.HasPrefix(stderrBuffer.String(), "internal error: unexpected plan\n") {
+				t.Errorf("Expected the panic to be logged, got %q", stderrBuffer.String())
+			}
+		})
+	}
+}
+
 func runCommand(dir string, args Args) (string, string, error) {
 
 	cmdArgs := make([]string, 0)
@@ -322,17 +1871,102 @@ func runCommand(dir string, args Args) (string, string, error) {
 	if args.Plan != "" {
 		cmdArgs = append(cmdArgs, fmt.Sprintf("-plan=%s", filepath.Join(dir, args.Plan)))
 	}
+	if args.Policy != "" {
+		cmdArgs = append(cmdArgs, fmt.Sprintf("-policy=%s", filepath.Join(dir, args.Policy)))
+	}
+	if args.FailOn != "" {
+		cmdArgs = append(cmdArgs, fmt.Sprintf("-fail-on=%s", args.FailOn))
+	}
+	if args.Format != "" {
+		cmdArgs = append(cmdArgs, fmt.Sprintf("-format=%s", args.Format))
+	}
+	if args.ConfigDir != "" {
+		cmdArgs = append(cmdArgs, fmt.Sprintf("-config-dir=%s", filepath.Join(dir, args.ConfigDir)))
+	}
+	if args.ReportAll {
+		cmdArgs = append(cmdArgs, "-report-all")
+	}
+	if args.ExitCode {
+		cmdArgs = append(cmdArgs, "-exit-code")
+	}
+	if args.GitHubEvent != "" {
+		cmdArgs = append(cmdArgs, fmt.Sprintf("-github-event=%s", filepath.Join(dir, args.GitHubEvent)))
+	}
+	if args.GitHubReviews != "" {
+		cmdArgs = append(cmdArgs, fmt.Sprintf("-github-reviews=%s", filepath.Join(dir, args.GitHubReviews)))
+	}
+	if args.RejectStaleApprovals {
+		cmdArgs = append(cmdArgs, "-reject-stale-approvals")
+	}
+	if args.Identities != "" {
+		cmdArgs = append(cmdArgs, fmt.Sprintf("-identities=%s", filepath.Join(dir, args.Identities)))
+	}
+	if args.Directory != "" {
+		cmdArgs = append(cmdArgs, fmt.Sprintf("-directory=%s", filepath.Join(dir, args.Directory)))
+	}
+	if args.Strict {
+		cmdArgs = append(cmdArgs, "-strict")
+	}
 
 	var stdoutBuffer, stderrBuffer strings.Builder
 
 	cmd := exec.Command("go", cmdArgs...)
 	cmd.Stdout = &stdoutBuffer
 	cmd.Stderr = &stderrBuffer
+	if args.Stdout != "" {
+		stdout, err := os.OpenFile(args.Stdout, os.O_WRONLY, 0)
+		if err != nil {
+			return "", "", err
+		}
+		defer stdout.Close()
+		cmd.Stdout = stdout
+	}
 
 	runErr := cmd.Run()
 	return stdoutBuffer.String(), stderrBuffer.String(), runErr
 }
 
+// evaluation returns the evaluation of a check for the resource, the type is taken from the address
+func evaluation(address, check string, outcome Outcome, resultErrors ...ResultError) CheckEvaluation {
+	parsed, _ := terraform.ParseAddress(address)
+	return CheckEvaluation{
+		Address: address,
+		Type:    parsed.Type,
+		Check:   check,
+		Outcome: outcome,
+		Errors:  append([]ResultError{}, resultErrors...),
+	}
+}
+
+// resourceReport returns the report of the resource, the type is taken from the address
+func resourceReport(address string, action terraform.ChangeKind, checks ...CheckReport) ResourceReport {
+	parsed, _ := terraform.ParseAddress(address)
+	return ResourceReport{Address: address, Type: parsed.Type, Action: action, Checks: checks}
+}
+
+func checkReport(check string, outcome Outcome, resultErrors []ResultError, evidence ...Evidence) CheckReport {
+	return CheckReport{
+		Check:    check,
+		Outcome:  outcome,
+		Errors:   append([]ResultError{}, resultErrors...),
+		Evidence: append([]Evidence{}, evidence...),
+	}
+}
+
+// withActions sets the action of each error of the result, in order
+func withActions(result Result, actions ...terraform.ChangeKind) Result {
+	result.Errors = slices.Clone(result.Errors)
+	for i, action := range actions {
+		result.Errors[i].Action = action
+	}
+	return result
+}
+
+func withSeverity(resultError ResultError, severity Severity) ResultError {
+	resultError.Severity = severity
+	return resultError
+}
+
 func assertOutput(t *testing.T, name, actual, expected string) {
 	actual = strings.TrimSpace(actual)
 	expected = strings.TrimSpace(expected)
@@ -346,6 +1980,18 @@ func assertOutput(t *testing.T, name, actual, expected string) {
 	}
 }
 
+// without returns a copy of the owner group without the given eligible approvers, e.g. the requester
+func (ownerGroup *OwnerGroup) without(users ...string) *OwnerGroup {
+	copied := *ownerGroup
+	copied.EligibleApprovers = []string{}
+	for _, approver := range ownerGroup.EligibleApprovers {
+		if !slices.Contains(users, approver) {
+			copied.EligibleApprovers = append(copied.EligibleApprovers, approver)
+		}
+	}
+	return &copied
+}
+
 func getTestPlan(t *testing.T, path string) *terraform.Plan {
 	content, readErr := os.ReadFile(path)
 	if readErr != nil {
@@ -378,6 +2024,74 @@ func TestUnit_findExternalIdentity(t *testing.T) {
 	})
 }
 
+func TestUnit_mergeIdentities(t *testing.T) {
+	plan := getTestPlan(t, "testdata/plan_with_known_owner_user_group_id.json")
+	conflicts := mergeIdentities(plan, []identity.Identity{
+		{InternalUserID: "u4e3706199a0", Aliases: []string{"alice-gitlab", "alice"}},
+		{InternalUserID: "u4e3b0f09999", Aliases: []string{"bob", "alice-gitlab"}},
+	})
+
+	t.Run("Resolves the aliases of the identities file", func(t *testing.T) {
+		user := findExternalIdentity("alice-gitlab", plan)
+		if user == nil || user.Values.InternalUserID != "u4e3706199a0" {
+			t.Errorf("Expected alice-gitlab to resolve to u4e3706199a0, but got %v", user)
+		}
+	})
+
+	t.Run("Keeps the identities of the state", func(t *testing.T) {
+		user := findExternalIdentity("bob", plan)
+		if user == nil || user.Values.InternalUserID != "u4e3b0f02414" {
+			t.Errorf("Expected bob to resolve to u4e3b0f02414, but got %v", user)
+		}
+	})
+
+	t.Run("Reports aliases mapped to more than one user ID", func(t *testing.T) {
+		expected := []IdentityConflict{
+			{Alias: "alice-gitlab", InternalUserIDs: []string{"u4e3706199a0", "u4e3b0f09999"}},
+			{Alias: "bob", InternalUserIDs: []string{"u4e3b0f02414", "u4e3b0f09999"}},
+		}
+		if !reflect.DeepEqual(conflicts, expected) {
+			t.Errorf("Expected %v, but got %v", expected, conflicts)
+		}
+	})
+}
+
+func TestUnit_mergeDirectory(t *testing.T) {
+	plan := getTestPlan(t, "testdata/plan_with_known_owner_user_group_id.json")
+	snapshot, err := directory.Load("testdata/directory/directory.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	mergeIdentities(plan, snapshot.Identities())
+	mergeDirectory(plan, snapshot)
+
+	t.Run("Adds the memberships of the snapshot", func(t *testing.T) {
+		ownerGroup := findOwnerGroupInState("ug4e3b20cee48", plan)
+		expected := []string{"alice", "alice@example.com", "bob", "charlie@example.com"}
+		if !reflect.DeepEqual(ownerGroup.EligibleApprovers, expected) {
+			t.Errorf("Expected %v, but got %v", expected, ownerGroup.EligibleApprovers)
+		}
+	})
+
+	t.Run("Adds the user groups missing from the state", func(t *testing.T) {
+		if name := findUserGroupNameInState("ug4e3b20f00d1", plan); name != "console-only" {
+			t.Errorf("Expected console-only, but got %q", name)
+		}
+	})
+
+	t.Run("Does not duplicate the memberships in state", func(t *testing.T) {
+		count := 0
+		for _, resource := range plan.PriorStateResources() {
+			if resource.Type == terraform.AivenOrganizationUserGroupMember && *resource.Values.UserID == "u4e3706199a0" {
+				count++
+			}
+		}
+		if count != 1 {
+			t.Errorf("Expected alice to be a member once, but got %d memberships", count)
+		}
+	})
+}
+
 func TestUnit_findApprovers(t *testing.T) {
 	plan := getTestPlan(t, "testdata/plan_with_known_owner_user_group_id.json")
 
@@ -416,19 +2130,119 @@ func TestUnit_findApprovers(t *testing.T) {
 	})
 }
 
+func TestUnit_findUnresolvedIdentities(t *testing.T) {
+	plan := getTestPlan(t, "testdata/plan_with_known_owner_user_group_id.json")
+
+	t.Run("Finds the requester and approvers without an external identity", func(t *testing.T) {
+		expected := []UnresolvedIdentity{{User: "frank", Role: RoleRequester}, {User: "charlie", Role: RoleApprover}}
+		unresolved := findUnresolvedIdentities("frank", []string{"bob", "charlie", "frank"}, plan)
+		if !reflect.DeepEqual(unresolved, expected) {
+			t.Errorf("Expected %v, but got %v", expected, unresolved)
+		}
+	})
+
+	t.Run("Does not report resolved identities", func(t *testing.T) {
+		if unresolved := findUnresolvedIdentities("alice", []string{"bob"}, plan); len(unresolved) != 0 {
+			t.Errorf("Expected no unresolved identities, but got %v", unresolved)
+		}
+	})
+}
+
+func TestUnit_findSelfApprovals(t *testing.T) {
+	plan := getTestPlan(t, "testdata/plan_with_known_owner_user_group_id.json")
+
+	t.Run("Finds the approval of the requester", func(t *testing.T) {
+		selfApprovals := findSelfApprovals([]string{"alice", "bob"}, "alice", plan)
+		if !reflect.DeepEqual(selfApprovals, []string{"alice"}) {
+			t.Errorf("Expected [alice], but got %v", selfApprovals)
+		}
+	})
+
+	t.Run("Does not report approvals of others", func(t *testing.T) {
+		if selfApprovals := findSelfApprovals([]string{"bob"}, "alice", plan); len(selfApprovals) != 0 {
+			t.Errorf("Expected no self-approvals, but got %v", selfApprovals)
+		}
+	})
+}
+
 func TestUnit_findOwnerAddressFromConfig(t *testing.T) {
 	plan := getTestPlan(t, "testdata/plan_with_known_owner_user_group_id.json")
 
 	t.Run("Return address if exists", func(t *testing.T) {
-		address := findOwnerAddressFromConfig("aiven_kafka_topic.foo", plan)
-		if *address != "aiven_organization_user_group.foo" {
+		reference, err := findOwnerAddressFromConfig("aiven_kafka_topic.foo", plan)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if reference.Address != "aiven_organization_user_group.foo" {
 			t.Error()
 		}
 	})
 
 	t.Run("Return nil if not found", func(t *testing.T) {
-		address := findOwnerAddressFromConfig("aiven_kafka_topic.test", plan)
-		if address != nil {
+		reference, err := findOwnerAddressFromConfig("aiven_kafka_topic.test", plan)
+		if reference != nil || err == nil {
+			t.Error()
+		}
+	})
+
+	t.Run("Return address across module boundaries", func(t *testing.T) {
+		modulePlan := getTestPlan(t, "testdata/plan_with_modules.json")
+		reference, err := findOwnerAddressFromConfig("module.payments.aiven_kafka_topic.refunds", modulePlan)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if reference.Address != "module.team.aiven_organization_user_group.new" {
+			t.Error()
+		}
+	})
+
+	unresolvablePlan := getTestPlan(t, "testdata/plan_with_unresolvable_owner_user_group_id.json")
+
+	t.Run("Return indexed address", func(t *testing.T) {
+		reference, err := findOwnerAddressFromConfig("aiven_kafka_topic.baz", unresolvablePlan)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if reference.Address != `aiven_organization_user_group.g["payments"]` {
+			t.Error()
+		}
+	})
+
+	t.Run("Return constant value of plan variables", func(t *testing.T) {
+		reference, err := findOwnerAddressFromConfig("aiven_kafka_topic.bar", unresolvablePlan)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if reference.Kind != terraform.ConstantReference || reference.Value != "ug4e3b20cee48" {
+			t.Error()
+		}
+	})
+
+	t.Run("Return address for instances of repeated resources", func(t *testing.T) {
+		keysPlan := getTestPlan(t, "testdata/plan_with_instance_keys.json")
+		reference, err := findOwnerAddressFromConfig(`aiven_kafka_topic.t["refunds"]`, keysPlan)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if reference.Address != `aiven_organization_user_group.g["refunds"]` {
+			t.Error()
+		}
+	})
+
+	t.Run("Return address for resources in module instances", func(t *testing.T) {
+		keysPlan := getTestPlan(t, "testdata/plan_with_instance_keys.json")
+		reference, err := findOwnerAddressFromConfig(`module.team["billing"].aiven_kafka_topic.invoices`, keysPlan)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if reference.Address != `aiven_organization_user_group.g["refunds"]` {
+			t.Error()
+		}
+	})
+
+	t.Run("Return error for locals", func(t *testing.T) {
+		reference, err := findOwnerAddressFromConfig("aiven_kafka_topic.foo", unresolvablePlan)
+		if reference != nil || !errors.Is(err, terraform.ErrUnsupportedReference) {
 			t.Error()
 		}
 	})
@@ -438,18 +2252,29 @@ func TestUnit_findUserAddressFromConfig(t *testing.T) {
 	plan := getTestPlan(t, "testdata/plan_with_known_owner_user_group_id.json")
 
 	t.Run("Return address if exists", func(t *testing.T) {
-		address := findUserAddressFromConfig("data.aiven_external_identity.alice", plan)
-		if address == nil {
-			t.Fatal()
+		reference, err := findUserAddressFromConfig("data.aiven_external_identity.alice", plan)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if reference.Address != "data.aiven_organization_user.foo" {
+			t.Error()
+		}
+	})
+
+	t.Run("Return address for instances of repeated data sources", func(t *testing.T) {
+		keysPlan := getTestPlan(t, "testdata/plan_with_instance_keys.json")
+		reference, err := findUserAddressFromConfig(`data.aiven_external_identity.users["bob"]`, keysPlan)
+		if err != nil {
+			t.Fatal(err)
 		}
-		if *address != "data.aiven_organization_user.foo" {
+		if reference.Address != `data.aiven_organization_user.users["bob"]` {
 			t.Error()
 		}
 	})
 
 	t.Run("Return nil if not found", func(t *testing.T) {
-		address := findUserAddressFromConfig("data.aiven_external_identity.frank", plan)
-		if address != nil {
+		reference, err := findUserAddressFromConfig("data.aiven_external_identity.frank", plan)
+		if reference != nil || err == nil {
 			t.Error()
 		}
 	})
@@ -487,6 +2312,44 @@ func TestUnit_isUserGroupMemberFromState(t *testing.T) {
 	})
 }
 
+func TestUnit_findOwnerGroupInState(t *testing.T) {
+	plan := getTestPlan(t, "testdata/plan_with_known_owner_user_group_id.json")
+
+	t.Run("Returns the group name and the external user IDs of its members", func(t *testing.T) {
+		actual := findOwnerGroupInState("ug4e3b20cee48", plan)
+		expected := &OwnerGroup{ID: "ug4e3b20cee48", Name: "foo", EligibleApprovers: []string{"alice", "bob"}}
+		if !reflect.DeepEqual(actual, expected) {
+			t.Errorf("Expected %v, but got %v", expected, actual)
+		}
+	})
+
+	t.Run("Returns no approvers if the group has no members", func(t *testing.T) {
+		actual := findOwnerGroupInState("ug4e3b20db73d", plan)
+		expected := &OwnerGroup{ID: "ug4e3b20db73d", Name: "bar", EligibleApprovers: []string{}}
+		if !reflect.DeepEqual(actual, expected) {
+			t.Errorf("Expected %v, but got %v", expected, actual)
+		}
+	})
+}
+
+func TestUnit_findOwnerGroupInConfig(t *testing.T) {
+	plan := getTestPlan(t, "testdata/plan_with_unknown_owner_user_group_id.json")
+
+	t.Run("Returns the members of a group created by the plan", func(t *testing.T) {
+		actual := findOwnerGroupInConfig("aiven_kafka_topic.foo", plan)
+		expected := &OwnerGroup{ID: "", Name: "foo", EligibleApprovers: []string{"alice", "bob"}}
+		if !reflect.DeepEqual(actual, expected) {
+			t.Errorf("Expected %v, but got %v", expected, actual)
+		}
+	})
+
+	t.Run("Returns nil if the owner can not be resolved", func(t *testing.T) {
+		if actual := findOwnerGroupInConfig("aiven_kafka_topic.missing", plan); actual != nil {
+			t.Errorf("Expected nil, but got %v", actual)
+		}
+	})
+}
+
 func TestUnit_isUserGroupMemberFromConfig(t *testing.T) {
 	plan := getTestPlan(t, "testdata/plan_with_unknown_owner_user_group_id.json")
 
@@ -540,6 +2403,76 @@ func TestUnit_isUserGroupMemberFromConfig(t *testing.T) {
 	})
 }
 
+func TestUnit_uniqueResultErrors(t *testing.T) {
+	t.Run("Sorts errors by address and rule ID", func(t *testing.T) {
+		actual := uniqueResultErrors([]ResultError{
+			newRequestError("aiven_kafka_topic.foo", &[]terraform.Tag{}),
+			newApproveError("aiven_kafka_topic.foo", &[]terraform.Tag{}),
+			newApproveError("aiven_kafka_topic.bar", &[]terraform.Tag{}),
+		})
+		expected := []ResultError{
+			newApproveError("aiven_kafka_topic.bar", &[]terraform.Tag{}),
+			newRequestError("aiven_kafka_topic.foo", &[]terraform.Tag{}),
+			newApproveError("aiven_kafka_topic.foo", &[]terraform.Tag{}),
+		}
+		if !reflect.DeepEqual(actual, expected) {
+			t.Errorf("Expected %v, but got %v", expected, actual)
+		}
+	})
+
+	t.Run("Keeps errors of resources with the same name in different modules", func(t *testing.T) {
+		actual := uniqueResultErrors([]ResultError{
+			newApproveError("module.payments.aiven_kafka_topic.orders", &[]terraform.Tag{}),
+			newApproveError("module.billing.aiven_kafka_topic.orders", &[]terraform.Tag{}),
+		})
+		expected := []ResultError{
+			newApproveError("module.billing.aiven_kafka_topic.orders", &[]terraform.Tag{}),
+			newApproveError("module.payments.aiven_kafka_topic.orders", &[]terraform.Tag{}),
+		}
+		if !reflect.DeepEqual(actual, expected) {
+			t.Errorf("Expected %v, but got %v", expected, actual)
+		}
+	})
+
+	t.Run("Removes duplicate errors of the same resource, the last one wins", func(t *testing.T) {
+		actual := uniqueResultErrors([]ResultError{
+			newApproveError("aiven_kafka_topic.foo", &[]terraform.Tag{{Key: "a", Value: "b"}}),
+			newApproveError("aiven_kafka_topic.foo", &[]terraform.Tag{}),
+		})
+		expected := []ResultError{newApproveError("aiven_kafka_topic.foo", &[]terraform.Tag{})}
+		if !reflect.DeepEqual(actual, expected) {
+			t.Errorf("Expected %v, but got %v", expected, actual)
+		}
+	})
+
+	t.Run("Keeps errors of the same resource for different owner groups", func(t *testing.T) {
+		oldOwner := &OwnerGroup{ID: "ug4e3b20cee48", Name: "a", EligibleApprovers: []string{"bob"}}
+		newOwner := &OwnerGroup{ID: "ug4e3b20db73d", Name: "b", EligibleApprovers: []string{"dave"}}
+		actual := uniqueResultErrors([]ResultError{
+			newApproveError("aiven_kafka_topic.foo", &[]terraform.Tag{}).withOwnerGroup(oldOwner),
+			newApproveError("aiven_kafka_topic.foo", &[]terraform.Tag{}).withOwnerGroup(newOwner),
+		})
+		expected := []ResultError{
+			newApproveError("aiven_kafka_topic.foo", &[]terraform.Tag{}).withOwnerGroup(oldOwner),
+			newApproveError("aiven_kafka_topic.foo", &[]terraform.Tag{}).withOwnerGroup(newOwner),
+		}
+		if !reflect.DeepEqual(actual, expected) {
+			t.Errorf("Expected %v, but got %v", expected, actual)
+		}
+	})
+
+	t.Run("Removes duplicate errors of the same resource, the most severe one wins", func(t *testing.T) {
+		actual := uniqueResultErrors([]ResultError{
+			newApproveError("aiven_kafka_topic.foo", &[]terraform.Tag{}),
+			withSeverity(newApproveError("aiven_kafka_topic.foo", &[]terraform.Tag{}), SeverityWarning),
+		})
+		expected := []ResultError{newApproveError("aiven_kafka_topic.foo", &[]terraform.Tag{})}
+		if !reflect.DeepEqual(actual, expected) {
+			t.Errorf("Expected %v, but got %v", expected, actual)
+		}
+	})
+}
+
 func TestUnit_newRequestError(t *testing.T) {
 	t.Run("Return error about requesting", func(t *testing.T) {
 		address := "aiven_kafka_topic.foo"
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+
+	"aiven/terraform/governance/compliance/checker/internal/policy"
+	"aiven/terraform/governance/compliance/checker/internal/terraform"
+)
+
+// CheckDefinition describes a check policies can refer to by name
+type CheckDefinition struct {
+	// Params are the names of the parameters the check accepts
+	Params []string
+	// Severity of the errors of the check, unless the policy overrides it
+	Severity Severity
+	// New returns the check configured with the parameters of a policy
+	New func(params policy.Params) (Check, error)
+}
+
+var checkDefinitions = map[string]CheckDefinition{
+	"change_is_requested_by_owner": {Severity: SeverityError, New: withoutParams(changeIsRequestedByOwner)},
+	"change_is_approved_by_owner":  {Severity: SeverityError, New: withoutParams(changeIsApprovedByOwner)},
+	"governance_access":            {Severity: SeverityError, New: withoutParams(governanceAccessCheck)},
+	"kafka_acl":                    {Severity: SeverityError, New: withoutParams(kafkaACLCheck)},
+	"required_tags":                {Severity: SeverityWarning, Params: []string{"keys"}, New: newRequiredTagsCheck},
+}
+
+// The default policy is used when no policy file is provided
+var defaultPolicy = policy.Policy{
+	Rules: []policy.Rule{
+		{
+			ResourceType: string(terraform.AivenKafkaTopic),
+			Checks:       []policy.Check{{Name: "change_is_requested_by_owner"}, {Name: "change_is_approved_by_owner"}},
+		},
+		{
+			// schemas are owned by the owner group of the topic of their subject
+			ResourceType: string(terraform.AivenKafkaSchema),
+			Checks:       []policy.Check{{Name: "change_is_requested_by_owner"}, {Name: "change_is_approved_by_owner"}},
+		},
+		{ResourceType: string(terraform.AivenExternalIdentity)},
+		{ResourceType: string(terraform.AivenOrganizationUserGroupMember)},
+		{
+			ResourceType: string(terraform.AivenGovernanceAccess),
+			Checks:       []policy.Check{{Name: "governance_access"}},
+		},
+		{
+			ResourceType: string(terraform.AivenKafkaACL),
+			Checks:       []policy.Check{{Name: "kafka_acl"}},
+		},
+		{
+			ResourceType: string(terraform.AivenKafkaNativeACL),
+			Checks:       []policy.Check{{Name: "kafka_acl"}},
+		},
+	},
+}
+
+// PolicyChecks are the checks of each rule of a policy, configured with their parameters
+type PolicyChecks struct {
+	policy *policy.Policy
+	checks map[*policy.Rule][]ConfiguredCheck
+}
+
+type ConfiguredCheck struct {
+	Name     string
+	Severity Severity
+	Check    Check
+}
+
+func newPolicyChecks(checkPolicy *policy.Policy) (*PolicyChecks, error) {
+	definitions := policy.Definitions{}
+	for name, definition := range checkDefinitions {
+		definitions[name] = definition.Params
+	}
+	if err := checkPolicy.Validate(definitions); err != nil {
+		return nil, err
+	}
+
+	policyChecks := &PolicyChecks{policy: checkPolicy, checks: map[*policy.Rule][]ConfiguredCheck{}}
+	for i := range checkPolicy.Rules {
+		rule := &checkPolicy.Rules[i]
+		configured := make([]ConfiguredCheck, 0, len(rule.Checks))
+		for _, ruleCheck := range rule.Checks {
+			definition := checkDefinitions[ruleCheck.Name]
+			check, err := definition.New(ruleCheck.Params)
+			if err != nil {
+				return nil, fmt.Errorf("invalid policy: rule %d: check %q: %w", i+1, ruleCheck.Name, err)
+			}
+
+			severity := definition.Severity
+			if ruleCheck.Severity != "" {
+				if severity, err = parseSeverity(ruleCheck.Severity); err != nil {
+					return nil, fmt.Errorf("invalid policy: rule %d: check %q: %w", i+1, ruleCheck.Name, err)
+				}
+			}
+
+			configured = append(configured, ConfiguredCheck{Name: ruleCheck.Name, Severity: severity, Check: check})
+		}
+		policyChecks.checks[rule] = configured
+	}
+	return policyChecks, nil
+}
+
+// forResourceChange returns the checks of the first rule matching the resource change, false if no rule matches the
+// resource. A resource moved to another project or service also gets the checks of the rule matching it before the
+// change, so that moving it under a relaxed rule doesn't skip the checks of the rule it is moved from. A check of
+// both rules runs once, with the more severe of its severities.
+func (policyChecks *PolicyChecks) forResourceChange(resourceChange terraform.ResourceChange) ([]ConfiguredCheck, bool) {
+	locations := [][2]string{}
+	if resourceChange.Change.Before != nil {
+		project, service := valuesLocation(resourceChange.Change.Before)
+		locations = append(locations, [2]string{project, service})
+	}
+	project, service := resourceLocation(resourceChange)
+	locations = append(locations, [2]string{project, service})
+
+	var matched []*policy.Rule
+	for _, location := range locations {
+		rule := policyChecks.policy.Match(string(resourceChange.Type), location[0], location[1])
+		if rule != nil && !slices.Contains(matched, rule) {
+			matched = append(matched, rule)
+		}
+	}
+	if len(matched) == 0 {
+		return nil, false
+	}
+
+	checks := []ConfiguredCheck{}
+	for _, rule := range matched {
+		for _, check := range policyChecks.checks[rule] {
+			i := slices.IndexFunc(checks, func(other ConfiguredCheck) bool { return other.Name == check.Name })
+			switch {
+			case i < 0:
+				checks = append(checks, check)
+			case !checks[i].Severity.AtLeast(check.Severity):
+				checks[i] = check
+			}
+		}
+	}
+	return checks, true
+}
+
+// resourceLocation returns the project and service of the resource after the change, or before it when deleted
+func resourceLocation(resourceChange terraform.ResourceChange) (string, string) {
+	values := resourceChange.Change.After
+	if values == nil {
+		values = resourceChange.Change.Before
+	}
+	return valuesLocation(values)
+}
+
+func valuesLocation(values *terraform.ResourceChangeValues) (string, string) {
+	var project, service string
+	if values != nil && values.Project != nil {
+		project = *values.Project
+	}
+	if values != nil && values.ServiceName != nil {
+		service = *values.ServiceName
+	}
+	return project, service
+}
+
+func withoutParams(check Check) func(policy.Params) (Check, error) {
+	return func(policy.Params) (Check, error) {
+		return check, nil
+	}
+}
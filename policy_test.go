@@ -0,0 +1,117 @@
+package main
+
+import (
+	"aiven/terraform/governance/compliance/checker/internal/policy"
+	"aiven/terraform/governance/compliance/checker/internal/terraform"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnit_newPolicyChecks(t *testing.T) {
+	t.Run("Default policy is valid", func(t *testing.T) {
+		checks, err := newPolicyChecks(&defaultPolicy)
+		assert.Nil(t, err)
+
+		topicChecks, ok := checks.forResourceChange(terraform.ResourceChange{Type: terraform.AivenKafkaTopic})
+		assert.True(t, ok)
+		assert.Len(t, topicChecks, 2)
+		assert.Equal(t, "change_is_requested_by_owner", topicChecks[0].Name)
+		assert.Equal(t, "change_is_approved_by_owner", topicChecks[1].Name)
+	})
+
+	t.Run("Resources moved to another project get the checks of the rules before and after the move", func(t *testing.T) {
+		checks, err := newPolicyChecks(&policy.Policy{Rules: []policy.Rule{
+			{
+				ResourceType: "aiven_kafka_topic",
+				Project:      "sandbox",
+				Checks:       []policy.Check{{Name: "change_is_requested_by_owner"}},
+			},
+			{
+				ResourceType: "aiven_kafka_topic",
+				Checks:       []policy.Check{{Name: "change_is_approved_by_owner"}},
+			},
+		}})
+		assert.Nil(t, err)
+
+		production, sandbox := "production", "sandbox"
+		topicChecks, ok := checks.forResourceChange(terraform.ResourceChange{
+			Type: terraform.AivenKafkaTopic,
+			Change: terraform.Change{
+				Before: &terraform.ResourceChangeValues{Project: &production},
+				After:  &terraform.ResourceChangeValues{Project: &sandbox},
+			},
+		})
+		assert.True(t, ok)
+		assert.Len(t, topicChecks, 2)
+		assert.Equal(t, "change_is_approved_by_owner", topicChecks[0].Name)
+		assert.Equal(t, "change_is_requested_by_owner", topicChecks[1].Name)
+	})
+
+	t.Run("Checks of both rules of a moved resource run once with the more severe severity", func(t *testing.T) {
+		checks, err := newPolicyChecks(&policy.Policy{Rules: []policy.Rule{
+			{
+				ResourceType: "aiven_kafka_topic",
+				Project:      "sandbox",
+				Checks:       []policy.Check{{Name: "change_is_requested_by_owner", Severity: "warning"}},
+			},
+			{
+				ResourceType: "aiven_kafka_topic",
+				Checks:       []policy.Check{{Name: "change_is_requested_by_owner"}},
+			},
+		}})
+		assert.Nil(t, err)
+
+		production, sandbox := "production", "sandbox"
+		topicChecks, ok := checks.forResourceChange(terraform.ResourceChange{
+			Type: terraform.AivenKafkaTopic,
+			Change: terraform.Change{
+				Before: &terraform.ResourceChangeValues{Project: &sandbox},
+				After:  &terraform.ResourceChangeValues{Project: &production},
+			},
+		})
+		assert.True(t, ok)
+		assert.Len(t, topicChecks, 1)
+		assert.Equal(t, SeverityError, topicChecks[0].Severity)
+	})
+
+	t.Run("Resources without a matching rule have no checks", func(t *testing.T) {
+		checks, err := newPolicyChecks(&defaultPolicy)
+		assert.Nil(t, err)
+
+		_, ok := checks.forResourceChange(terraform.ResourceChange{Type: "aiven_kafka"})
+		assert.False(t, ok)
+	})
+
+	t.Run("Returns error if the check rejects its parameters", func(t *testing.T) {
+		_, err := newPolicyChecks(&policy.Policy{Rules: []policy.Rule{
+			{
+				ResourceType: "aiven_kafka_topic",
+				Checks:       []policy.Check{{Name: "required_tags", Params: policy.Params{"keys": " "}}},
+			},
+		}})
+		assert.EqualError(t, err, `invalid policy: rule 1: check "required_tags": keys parameter is required`)
+	})
+}
+
+func TestUnit_resourceLocation(t *testing.T) {
+	project, service := "testproject-hpo9", "kafka1"
+
+	t.Run("Uses the values after the change", func(t *testing.T) {
+		resourceChange := terraform.ResourceChange{Change: terraform.Change{
+			After: &terraform.ResourceChangeValues{Project: &project, ServiceName: &service},
+		}}
+		actualProject, actualService := resourceLocation(resourceChange)
+		assert.Equal(t, project, actualProject)
+		assert.Equal(t, service, actualService)
+	})
+
+	t.Run("Uses the values before the change for deletes", func(t *testing.T) {
+		resourceChange := terraform.ResourceChange{Change: terraform.Change{
+			Before: &terraform.ResourceChangeValues{Project: &project, ServiceName: &service},
+		}}
+		actualProject, actualService := resourceLocation(resourceChange)
+		assert.Equal(t, project, actualProject)
+		assert.Equal(t, service, actualService)
+	})
+}
@@ -1,13 +1,219 @@
 package main
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"aiven/terraform/governance/compliance/checker/internal/directory"
+	"aiven/terraform/governance/compliance/checker/internal/terraform"
+)
 
 type Result struct {
 	Ok     bool          `json:"ok"`
 	Errors []ResultError `json:"errors"`
+	// Warnings are the findings below the failure threshold, they don't affect Ok
+	Warnings []ResultError `json:"warnings"`
+	// Evaluations are the outcomes of every check for every resource change, including the passing ones
+	Evaluations []CheckEvaluation `json:"-"`
+	// Resources are the evaluated resources with all their checks, only reported with -report-all
+	Resources []ResourceReport `json:"resources,omitempty"`
+	// Diagnostics are findings about the input of the checks, only reported if there are any
+	Diagnostics *Diagnostics `json:"diagnostics,omitempty"`
+	// Directory is the directory snapshot memberships are taken from besides the state, only reported with -directory
+	Directory *DirectoryReport `json:"directory,omitempty"`
+}
+
+// DirectoryReport tells how old the directory snapshot is, memberships changed since it was taken are not known
+type DirectoryReport struct {
+	GeneratedAt time.Time `json:"generated_at"`
+	AgeSeconds  int64     `json:"age_seconds"`
+}
+
+func newDirectoryReport(snapshot *directory.Snapshot, now time.Time) *DirectoryReport {
+	return &DirectoryReport{
+		GeneratedAt: snapshot.GeneratedAt,
+		AgeSeconds:  int64(now.Sub(snapshot.GeneratedAt) / time.Second),
+	}
+}
+
+// Diagnostics are findings about the input of the checks rather than the resources, they don't affect Ok
+type Diagnostics struct {
+	// IdentityConflicts are aliases mapped to more than one internal user ID by the state and the identities file
+	IdentityConflicts []IdentityConflict `json:"identity_conflicts,omitempty"`
+	// UnresolvedIdentities are the requester and approvers without an aiven_external_identity, they can't satisfy
+	// any requirement
+	UnresolvedIdentities []UnresolvedIdentity `json:"unresolved_identities,omitempty"`
+	// IgnoredSelfApprovals are the approvals of the requester, who can't approve their own change
+	IgnoredSelfApprovals []string `json:"ignored_self_approvals,omitempty"`
+}
+
+func (diagnostics Diagnostics) empty() bool {
+	return len(diagnostics.IdentityConflicts) == 0 && len(diagnostics.UnresolvedIdentities) == 0 &&
+		len(diagnostics.IgnoredSelfApprovals) == 0
+}
+
+// messages describes each diagnostic in a sentence, for the formats written for people
+func (diagnostics Diagnostics) messages() []string {
+	messages := []string{}
+	for _, unresolved := range diagnostics.UnresolvedIdentities {
+		messages = append(messages, fmt.Sprintf(
+			"%s %s has no aiven_external_identity, map it in the state or with an identities file",
+			unresolved.Role, unresolved.User,
+		))
+	}
+	for _, approver := range diagnostics.IgnoredSelfApprovals {
+		messages = append(messages, fmt.Sprintf(
+			"approval by %s is ignored, requesters can't approve their own change", approver,
+		))
+	}
+	for _, conflict := range diagnostics.IdentityConflicts {
+		messages = append(messages, fmt.Sprintf("%s is mapped to more than one user, %s is used",
+			conflict.Alias, conflict.InternalUserIDs[0],
+		))
+	}
+	return messages
+}
+
+type IdentityRole string
+
+const (
+	RoleRequester IdentityRole = "requester"
+	RoleApprover  IdentityRole = "approver"
+)
+
+type UnresolvedIdentity struct {
+	User string       `json:"user"`
+	Role IdentityRole `json:"role"`
+}
+
+type IdentityConflict struct {
+	Alias string `json:"alias"`
+	// InternalUserIDs are the user IDs the alias is mapped to, the first one is used. IDs from the state come first,
+	// followed by the ones of the identities file in order.
+	InternalUserIDs []string `json:"internal_user_ids"`
+}
+
+// CheckEvaluation is the outcome of running a check for a resource change
+type CheckEvaluation struct {
+	Address string                 `json:"address"`
+	Type    terraform.ResourceType `json:"type"`
+	Action  terraform.ChangeKind   `json:"action"`
+	// Check is the name of the check, empty if no checks run for the resource
+	Check   string        `json:"check"`
+	Outcome Outcome       `json:"outcome"`
+	Errors  []ResultError `json:"errors"`
+	// Evidence are the requirements of the check that are satisfied and who satisfied them
+	Evidence []Evidence `json:"evidence"`
+}
+
+// ResourceReport is a resource change and the outcome of every check run for it
+type ResourceReport struct {
+	Address string                 `json:"address"`
+	Type    terraform.ResourceType `json:"type"`
+	Action  terraform.ChangeKind   `json:"action"`
+	Checks  []CheckReport          `json:"checks"`
+}
+
+type CheckReport struct {
+	// Check is the name of the check, empty if no checks run for the resource
+	Check    string        `json:"check"`
+	Outcome  Outcome       `json:"outcome"`
+	Errors   []ResultError `json:"errors"`
+	Evidence []Evidence    `json:"evidence"`
+}
+
+type Outcome string
+
+const (
+	OutcomePassed Outcome = "passed"
+	OutcomeFailed Outcome = "failed"
+	// OutcomeWarned is a check that failed with errors below the failure threshold only
+	OutcomeWarned Outcome = "warned"
+	// OutcomeSkipped is a resource no checks run for
+	OutcomeSkipped Outcome = "skipped"
+)
+
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityInfo    Severity = "info"
+)
+
+var severityRanks = map[Severity]int{
+	SeverityInfo:    0,
+	SeverityWarning: 1,
+	SeverityError:   2,
+}
+
+func parseSeverity(value string) (Severity, error) {
+	severity := Severity(value)
+	if _, ok := severityRanks[severity]; !ok {
+		return "", fmt.Errorf("unknown severity %q", value)
+	}
+	return severity, nil
+}
+
+// AtLeast reports whether the severity is the same or more severe than the threshold
+func (severity Severity) AtLeast(threshold Severity) bool {
+	return severityRanks[severity] >= severityRanks[threshold]
+}
+
+// add sorts the error into errors or warnings depending on the failure threshold
+func (result *Result) add(err ResultError, failOn Severity) {
+	if err.Severity.AtLeast(failOn) {
+		result.Errors = append(result.Errors, err)
+		result.Ok = false
+		return
+	}
+	result.Warnings = append(result.Warnings, err)
+}
+
+// addEvaluation records the evaluation, a failed check is only a warning if none of its errors reach the threshold
+func (result *Result) addEvaluation(evaluation CheckEvaluation, failOn Severity) {
+	if evaluation.Outcome == OutcomeFailed && !slices.ContainsFunc(evaluation.Errors, func(err ResultError) bool {
+		return err.Severity.AtLeast(failOn)
+	}) {
+		evaluation.Outcome = OutcomeWarned
+	}
+	result.Evaluations = append(result.Evaluations, evaluation)
+}
+
+// reportAll groups the evaluations by resource, in the order of the resource changes in the plan
+func (result *Result) reportAll() {
+	result.Resources = []ResourceReport{}
+	resourceIndex := map[string]int{}
+	for _, evaluation := range result.Evaluations {
+		i, ok := resourceIndex[evaluation.Address]
+		if !ok {
+			i = len(result.Resources)
+			resourceIndex[evaluation.Address] = i
+			result.Resources = append(result.Resources, ResourceReport{
+				Address: evaluation.Address,
+				Type:    evaluation.Type,
+				Action:  evaluation.Action,
+			})
+		}
+		result.Resources[i].Checks = append(result.Resources[i].Checks, CheckReport{
+			Check:    evaluation.Check,
+			Outcome:  evaluation.Outcome,
+			Errors:   evaluation.Errors,
+			Evidence: evaluation.Evidence,
+		})
+	}
 }
 
 func (result Result) toJSON() string {
+	// consumers expect lists rather than nulls
+	if result.Errors == nil {
+		result.Errors = []ResultError{}
+	}
+	if result.Warnings == nil {
+		result.Warnings = []ResultError{}
+	}
 	encoded, _ := json.Marshal(result)
 	return string(encoded)
 }
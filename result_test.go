@@ -1,8 +1,11 @@
 package main
 
 import (
+	"aiven/terraform/governance/compliance/checker/internal/directory"
 	"aiven/terraform/governance/compliance/checker/internal/terraform"
+	"reflect"
 	"testing"
+	"time"
 )
 
 func TestResultToJSON(t *testing.T) {
@@ -17,7 +20,7 @@ func TestResultToJSON(t *testing.T) {
 				Ok:     true,
 				Errors: []ResultError{},
 			},
-			expected: `{"ok":true,"errors":[]}`,
+			expected: `{"ok":true,"errors":[],"warnings":[]}`,
 		},
 		{
 			name: "Failure result with errors",
@@ -26,11 +29,31 @@ func TestResultToJSON(t *testing.T) {
 				Errors: []ResultError{
 					{Error: "Error 1"},
 					{Error: "Error 2", Address: "Address 2"},
-					{Error: "Error 3", Address: "Address 3", Tags: []terraform.Tag{{Key: "Key 1", Value: "Value 1"}}},
+					{
+						Error:       "Error 3",
+						Address:     "Address 3",
+						Tags:        []terraform.Tag{{Key: "Key 1", Value: "Value 1"}},
+						Severity:    SeverityError,
+						RuleID:      "AKG000-test",
+						Remediation: "Fix it",
+						DocsURL:     "https://example.com/akg000-test",
+					},
 				},
 			},
 			//nolint: lll
-			expected: `{"ok":false,"errors":[{"error":"Error 1","address":"","tags":null},{"error":"Error 2","address":"Address 2","tags":null},{"error":"Error 3","address":"Address 3","tags":[{"key":"Key 1","value":"Value 1"}]}]}`,
+			expected: `{"ok":false,"errors":[{"error":"Error 1","address":"","tags":null,"severity":"","rule_id":"","remediation":"","docs_url":""},{"error":"Error 2","address":"Address 2","tags":null,"severity":"","rule_id":"","remediation":"","docs_url":""},{"error":"Error 3","address":"Address 3","tags":[{"key":"Key 1","value":"Value 1"}],"severity":"error","rule_id":"AKG000-test","remediation":"Fix it","docs_url":"https://example.com/akg000-test"}],"warnings":[]}`,
+		},
+		{
+			name: "Result with warnings",
+			result: Result{
+				Ok:     true,
+				Errors: []ResultError{},
+				Warnings: []ResultError{
+					{Error: "Warning 1", Address: "Address 1", Tags: []terraform.Tag{}, Severity: SeverityWarning},
+				},
+			},
+			//nolint: lll
+			expected: `{"ok":true,"errors":[],"warnings":[{"error":"Warning 1","address":"Address 1","tags":[],"severity":"warning","rule_id":"","remediation":"","docs_url":""}]}`,
 		},
 	}
 
@@ -45,3 +68,70 @@ func TestResultToJSON(t *testing.T) {
 		})
 	}
 }
+
+func TestResultAdd(t *testing.T) {
+	t.Run("Errors at or above the threshold fail the result", func(t *testing.T) {
+		result := Result{Ok: true}
+		result.add(ResultError{Error: "Error 1", Severity: SeverityWarning}, SeverityWarning)
+		if result.Ok || len(result.Errors) != 1 || len(result.Warnings) != 0 {
+			t.Errorf("Expected a failed result with one error, but got %v", result)
+		}
+	})
+
+	t.Run("Errors below the threshold are warnings", func(t *testing.T) {
+		result := Result{Ok: true}
+		result.add(ResultError{Error: "Error 1", Severity: SeverityInfo}, SeverityWarning)
+		if !result.Ok || len(result.Errors) != 0 || len(result.Warnings) != 1 {
+			t.Errorf("Expected an ok result with one warning, but got %v", result)
+		}
+	})
+}
+
+func TestResultAddEvaluation(t *testing.T) {
+	t.Run("Failed checks with errors below the threshold only are warned", func(t *testing.T) {
+		result := Result{Ok: true}
+		evaluation := CheckEvaluation{
+			Outcome: OutcomeFailed,
+			Errors:  []ResultError{{Error: "Error 1", Severity: SeverityWarning}},
+		}
+		result.addEvaluation(evaluation, SeverityError)
+		result.addEvaluation(evaluation, SeverityWarning)
+		if result.Evaluations[0].Outcome != OutcomeWarned || result.Evaluations[1].Outcome != OutcomeFailed {
+			t.Errorf("Expected a warned and a failed evaluation, but got %v", result.Evaluations)
+		}
+	})
+
+	t.Run("Passed and skipped checks keep their outcome", func(t *testing.T) {
+		result := Result{Ok: true}
+		result.addEvaluation(CheckEvaluation{Outcome: OutcomePassed}, SeverityError)
+		result.addEvaluation(CheckEvaluation{Outcome: OutcomeSkipped}, SeverityError)
+		if result.Evaluations[0].Outcome != OutcomePassed || result.Evaluations[1].Outcome != OutcomeSkipped {
+			t.Errorf("Expected a passed and a skipped evaluation, but got %v", result.Evaluations)
+		}
+	})
+}
+
+func TestResultReportAll(t *testing.T) {
+	result := Result{Ok: true}
+	result.addEvaluation(CheckEvaluation{Address: "a", Check: "check_1", Outcome: OutcomePassed}, SeverityError)
+	result.addEvaluation(CheckEvaluation{Address: "b", Outcome: OutcomeSkipped}, SeverityError)
+	result.addEvaluation(CheckEvaluation{Address: "a", Check: "check_2", Outcome: OutcomePassed}, SeverityError)
+	result.reportAll()
+
+	if len(result.Resources) != 2 || result.Resources[0].Address != "a" || result.Resources[1].Address != "b" {
+		t.Fatalf("Expected the resources a and b in the order of their first evaluation, but got %v", result.Resources)
+	}
+	if len(result.Resources[0].Checks) != 2 || result.Resources[0].Checks[1].Check != "check_2" {
+		t.Errorf("Expected both checks of resource a, but got %v", result.Resources[0].Checks)
+	}
+}
+
+func TestNewDirectoryReport(t *testing.T) {
+	generatedAt := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	report := newDirectoryReport(&directory.Snapshot{GeneratedAt: generatedAt}, generatedAt.Add(36*time.Hour))
+
+	expected := &DirectoryReport{GeneratedAt: generatedAt, AgeSeconds: 36 * 60 * 60}
+	if !reflect.DeepEqual(report, expected) {
+		t.Errorf("Expected %v, but got %v", expected, report)
+	}
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Rule identifies the kind of an error in the report. Rule IDs are stable so that tooling can rely on them
+// instead of matching error messages.
+type Rule struct {
+	ID string
+	// Description is a short summary of what the rule requires
+	Description string
+	// Remediation tells the requester how to resolve the error
+	Remediation string
+}
+
+const rulesDocsURL = "https://github.com/aiven/aiven-terraform-governance-compliance-checker#"
+
+// rules is the registry of all rules, checks register the rules of their errors with registerRule
+var rules = map[string]Rule{}
+
+func registerRule(rule Rule) Rule {
+	if _, ok := rules[rule.ID]; ok {
+		panic(fmt.Sprintf("rule %s is registered more than once", rule.ID))
+	}
+	rules[rule.ID] = rule
+	return rule
+}
+
+// DocsURL returns the link to the documentation of the rule in the README
+func (rule Rule) DocsURL() string {
+	return rulesDocsURL + strings.ToLower(rule.ID)
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnit_Rules(t *testing.T) {
+	t.Run("Registered rules are complete", func(t *testing.T) {
+		pattern := regexp.MustCompile(`^AKG\d{3}-[a-z-]+$`)
+		for id, rule := range rules {
+			assert.Regexp(t, pattern, id)
+			assert.NotEmpty(t, rule.Description, id)
+			assert.NotEmpty(t, rule.Remediation, id)
+		}
+	})
+
+	t.Run("Links the rule to its README section", func(t *testing.T) {
+		assert.Equal(t,
+			"https://github.com/aiven/aiven-terraform-governance-compliance-checker#akg001-requester-owner",
+			ruleRequesterOwner.DocsURL(),
+		)
+	})
+
+	t.Run("Rule IDs can only be registered once", func(t *testing.T) {
+		assert.Panics(t, func() { registerRule(Rule{ID: ruleRequesterOwner.ID}) })
+	})
+}
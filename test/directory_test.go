@@ -0,0 +1,54 @@
+package test
+
+import (
+	"aiven/terraform/governance/compliance/checker/internal/directory"
+	"aiven/terraform/governance/compliance/checker/internal/identity"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDirectory_Load(t *testing.T) {
+
+	t.Run("Reads the users and user groups of the snapshot", func(t *testing.T) {
+		snapshot, err := directory.Load("../testdata/directory/directory.json")
+		assert.Nil(t, err)
+		assert.Equal(t, time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC), snapshot.GeneratedAt)
+		assert.Equal(t, 2, len(snapshot.Users))
+		assert.Equal(t, directory.UserGroup{
+			UserGroupID:   "ug4e3b20f00d1",
+			UserGroupName: "console-only",
+			Members:       []directory.Member{{UserID: "u4e3b0f0cccc"}},
+		}, snapshot.UserGroups[1])
+	})
+
+	t.Run("Maps the email of each user to its user ID", func(t *testing.T) {
+		snapshot, err := directory.Load("../testdata/directory/directory.json")
+		assert.Nil(t, err)
+		assert.Equal(t, []identity.Identity{
+			{InternalUserID: "u4e3706199a0", Aliases: []string{"alice@example.com"}},
+			{InternalUserID: "u4e3b0f0cccc", Aliases: []string{"charlie@example.com"}},
+		}, snapshot.Identities())
+	})
+
+	t.Run("Returns error if the snapshot has no generation time", func(t *testing.T) {
+		_, err := directory.Load("../testdata/directory/directory_missing_generated_at.json")
+		assert.Equal(t, "invalid directory file: generated_at is required", err.Error())
+	})
+
+	t.Run("Returns error if a user group has no ID", func(t *testing.T) {
+		_, err := directory.Load("../testdata/directory/directory_missing_group_id.json")
+		assert.Equal(t, "invalid directory file: user group 1: user_group_id is required", err.Error())
+	})
+
+	t.Run("Returns error if the file is not JSON", func(t *testing.T) {
+		_, err := directory.Load("../testdata/not_json.py")
+		assert.Regexp(t, "^invalid directory file: ", err.Error())
+	})
+
+	t.Run("Returns error if path does not point to a file", func(t *testing.T) {
+		_, err := directory.Load("not-a-file")
+		assert.Equal(t, "invalid directory file", err.Error())
+	})
+}
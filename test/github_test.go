@@ -0,0 +1,94 @@
+package test
+
+import (
+	"aiven/terraform/governance/compliance/checker/internal/github"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGitHub_NewEvent(t *testing.T) {
+
+	t.Run("Reads the pull request of the event", func(t *testing.T) {
+		event, err := github.NewEvent("../testdata/github/pull_request_event.json")
+		assert.Nil(t, err)
+		assert.Equal(t, "alice", event.PullRequest.User.Login)
+		assert.Equal(t, "9f2c4e1a7b3d5f6e8c0a1b2c3d4e5f6a7b8c9d0e", event.PullRequest.Head.SHA)
+	})
+
+	t.Run("Returns error if the event is not about a pull request", func(t *testing.T) {
+		_, err := github.NewEvent("../testdata/github/push_event.json")
+		assert.Equal(t, "invalid GitHub event file: not a pull request event", err.Error())
+	})
+
+	t.Run("Returns error if path does not point to valid json file", func(t *testing.T) {
+		_, err := github.NewEvent("../testdata/not_json.py")
+		assert.Equal(t, "invalid GitHub event file", err.Error())
+	})
+}
+
+func TestGitHub_Approvers(t *testing.T) {
+	review := func(login string, state github.ReviewState) github.Review {
+		return github.Review{User: github.User{Login: login}, State: state}
+	}
+
+	t.Run("Reads the reviews file", func(t *testing.T) {
+		reviews, err := github.NewReviews("../testdata/github/reviews.json")
+		assert.Nil(t, err)
+		assert.Len(t, reviews, 5)
+		assert.Equal(t, []string{"bob"}, github.Approvers(reviews))
+	})
+
+	t.Run("Keeps the approval of a user commenting later", func(t *testing.T) {
+		reviews := []github.Review{review("bob", github.ReviewApproved), review("bob", github.ReviewCommented)}
+		assert.Equal(t, []string{"bob"}, github.Approvers(reviews))
+	})
+
+	t.Run("Revokes the approval of a user requesting changes later", func(t *testing.T) {
+		reviews := []github.Review{review("bob", github.ReviewApproved), review("bob", github.ReviewChangesRequested)}
+		assert.Equal(t, []string{}, github.Approvers(reviews))
+	})
+
+	t.Run("Revokes dismissed approvals", func(t *testing.T) {
+		reviews := []github.Review{review("bob", github.ReviewDismissed)}
+		assert.Equal(t, []string{}, github.Approvers(reviews))
+	})
+
+	t.Run("Approves again after a request for changes", func(t *testing.T) {
+		reviews := []github.Review{
+			review("charlie", github.ReviewChangesRequested),
+			review("charlie", github.ReviewApproved),
+			review("bob", github.ReviewApproved),
+		}
+		assert.Equal(t, []string{"bob", "charlie"}, github.Approvers(reviews))
+	})
+
+	t.Run("Orders the reviews by the time they are submitted at", func(t *testing.T) {
+		reviews, err := github.NewReviews("../testdata/github/reviews_unordered.json")
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"charlie"}, github.Approvers(reviews))
+	})
+
+	t.Run("Returns error if path does not point to valid json file", func(t *testing.T) {
+		_, err := github.NewReviews("../testdata/not_json.py")
+		assert.Equal(t, "invalid GitHub reviews file", err.Error())
+	})
+}
+
+func TestGitHub_StaleApprovals(t *testing.T) {
+	reviews, err := github.NewReviews("../testdata/github/reviews_stale.json")
+	assert.Nil(t, err)
+
+	t.Run("Returns the commits of approvals for an earlier commit than the head", func(t *testing.T) {
+		stale := github.StaleApprovals(reviews, "9f2c4e1a7b3d5f6e8c0a1b2c3d4e5f6a7b8c9d0e")
+		assert.Equal(t, map[string]string{"bob": "abc1234f5e6d7c8b9a0f1e2d3c4b5a6978877665"}, stale)
+	})
+
+	t.Run("Ignores reviews that don't approve", func(t *testing.T) {
+		reviews := []github.Review{
+			{User: github.User{Login: "bob"}, State: github.ReviewApproved, CommitID: "abc1234"},
+			{User: github.User{Login: "bob"}, State: github.ReviewChangesRequested, CommitID: "abc1234"},
+		}
+		assert.Empty(t, github.StaleApprovals(reviews, "9f2c4e1"))
+	})
+}
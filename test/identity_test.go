@@ -0,0 +1,45 @@
+package test
+
+import (
+	"aiven/terraform/governance/compliance/checker/internal/identity"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdentity_Load(t *testing.T) {
+
+	t.Run("Reads the identities of a YAML file", func(t *testing.T) {
+		identities, err := identity.Load("../testdata/identities/identities.yaml")
+		assert.Nil(t, err)
+		assert.Equal(t, []identity.Identity{
+			{InternalUserID: "u4e3706199a0", Aliases: []string{"alice-gitlab", "alice@example.com"}},
+			{InternalUserID: "u4e3b0f09999", Aliases: []string{"bob"}},
+		}, identities)
+	})
+
+	t.Run("Reads an identity per row of a CSV file", func(t *testing.T) {
+		identities, err := identity.Load("../testdata/identities/identities.csv")
+		assert.Nil(t, err)
+		assert.Equal(t, []identity.Identity{
+			{InternalUserID: "u4e3706199a0", Aliases: []string{"alice-gitlab"}},
+			{InternalUserID: "u4e3706199a0", Aliases: []string{"alice@example.com"}},
+			{InternalUserID: "u4e3b0f09999", Aliases: []string{"bob"}},
+		}, identities)
+	})
+
+	t.Run("Returns error if an identity has no internal user ID", func(t *testing.T) {
+		_, err := identity.Load("../testdata/identities/identities_missing_user_id.yaml")
+		assert.Equal(t, "invalid identities file: identity 1: internal_user_id is required", err.Error())
+	})
+
+	t.Run("Returns error if the CSV header is unknown", func(t *testing.T) {
+		_, err := identity.Load("../testdata/identities/identities_invalid_header.csv")
+		assert.Equal(t, "invalid identities file: the header must be alias,internal_user_id", err.Error())
+	})
+
+	t.Run("Returns error if path does not point to a file", func(t *testing.T) {
+		_, err := identity.Load("not-a-file")
+		assert.Equal(t, "invalid identities file", err.Error())
+	})
+}
@@ -17,6 +17,135 @@ func TestInput_NewInput(t *testing.T) {
 		assert.Equal(t, args.Approvers, []string{"bob", "charlie"})
 	})
 
+	t.Run("Parses the policy path", func(t *testing.T) {
+		args, err := input.NewInput([]string{"-plan=plan.json", "-policy=policy.yaml"})
+		assert.Equal(t, err, nil)
+		assert.Equal(t, args.Policy, "policy.yaml")
+	})
+
+	t.Run("Parses the fail-on threshold and defaults it to error", func(t *testing.T) {
+		args, err := input.NewInput([]string{"-plan=plan.json", "-fail-on=warning"})
+		assert.Equal(t, err, nil)
+		assert.Equal(t, args.FailOn, "warning")
+
+		args, err = input.NewInput([]string{"-plan=plan.json"})
+		assert.Equal(t, err, nil)
+		assert.Equal(t, args.FailOn, "error")
+	})
+
+	t.Run("Parses the report-all switch and defaults it to off", func(t *testing.T) {
+		args, err := input.NewInput([]string{"-plan=plan.json", "-report-all"})
+		assert.Equal(t, err, nil)
+		assert.Equal(t, args.ReportAll, true)
+
+		args, err = input.NewInput([]string{"-plan=plan.json"})
+		assert.Equal(t, err, nil)
+		assert.Equal(t, args.ReportAll, false)
+	})
+
+	t.Run("Parses the exit-code switch and defaults it to off", func(t *testing.T) {
+		args, err := input.NewInput([]string{"-plan=plan.json", "-exit-code"})
+		assert.Equal(t, err, nil)
+		assert.Equal(t, args.ExitCode, true)
+
+		args, err = input.NewInput([]string{"-plan=plan.json"})
+		assert.Equal(t, err, nil)
+		assert.Equal(t, args.ExitCode, false)
+	})
+
+	t.Run("Returns the input parsed so far with the error", func(t *testing.T) {
+		args, err := input.NewInput([]string{"-exit-code", "-requester=alice"})
+		assert.Equal(t, err.Error(), "plan is a required argument")
+		assert.Equal(t, args.ExitCode, true)
+
+		args, err = input.NewInput([]string{"-exit-code", "-unknown"})
+		assert.Equal(t, err.Error(), "invalid arguments")
+		assert.Equal(t, args.ExitCode, true)
+	})
+
+	t.Run("Derives the requester and approvers from the GitHub event and reviews", func(t *testing.T) {
+		args, err := input.NewInput([]string{
+			"-plan=plan.json",
+			"-github-event=../testdata/github/pull_request_event.json",
+			"-github-reviews=../testdata/github/reviews.json",
+		})
+		assert.Equal(t, err, nil)
+		assert.Equal(t, args.Requester, "alice")
+		assert.Equal(t, args.Approvers, []string{"bob"})
+	})
+
+	t.Run("Prefers the requester and approvers args over the GitHub files", func(t *testing.T) {
+		args, err := input.NewInput([]string{
+			"-plan=plan.json",
+			"-requester=charlie",
+			"-approvers=frank",
+			"-github-event=../testdata/github/pull_request_event.json",
+			"-github-reviews=../testdata/github/reviews.json",
+		})
+		assert.Equal(t, err, nil)
+		assert.Equal(t, args.Requester, "charlie")
+		assert.Equal(t, args.Approvers, []string{"frank"})
+	})
+
+	t.Run("Rejects the stale approvals of the GitHub reviews", func(t *testing.T) {
+		args, err := input.NewInput([]string{
+			"-plan=plan.json",
+			"-github-event=../testdata/github/pull_request_event.json",
+			"-github-reviews=../testdata/github/reviews_stale.json",
+			"-reject-stale-approvals",
+		})
+		assert.Equal(t, err, nil)
+		assert.Equal(t, args.HeadSHA, "9f2c4e1a7b3d5f6e8c0a1b2c3d4e5f6a7b8c9d0e")
+		assert.Equal(t, args.Approvers, []string{"charlie"})
+		assert.Equal(t, args.StaleApprovals, map[string]string{"bob": "abc1234f5e6d7c8b9a0f1e2d3c4b5a6978877665"})
+	})
+
+	t.Run("Counts stale approvals unless they are rejected", func(t *testing.T) {
+		args, err := input.NewInput([]string{
+			"-plan=plan.json",
+			"-github-reviews=../testdata/github/reviews_stale.json",
+			"-head-sha=9f2c4e1a7b3d5f6e8c0a1b2c3d4e5f6a7b8c9d0e",
+		})
+		assert.Equal(t, err, nil)
+		assert.Equal(t, args.Approvers, []string{"bob", "charlie"})
+		assert.Nil(t, args.StaleApprovals)
+	})
+
+	t.Run("Returns error if stale approvals are rejected without a head SHA", func(t *testing.T) {
+		_, err := input.NewInput([]string{
+			"-plan=plan.json",
+			"-github-reviews=../testdata/github/reviews_stale.json",
+			"-reject-stale-approvals",
+		})
+		assert.Equal(t, err.Error(), "head SHA is required to reject stale approvals")
+	})
+
+	t.Run("Returns error if the GitHub files are invalid", func(t *testing.T) {
+		_, err := input.NewInput([]string{"-plan=plan.json", "-github-event=../testdata/not_json.py"})
+		assert.Equal(t, err.Error(), "invalid GitHub event file")
+
+		_, err = input.NewInput([]string{"-plan=plan.json", "-github-reviews=../testdata/not_json.py"})
+		assert.Equal(t, err.Error(), "invalid GitHub reviews file")
+	})
+
+	t.Run("Parses the identities path", func(t *testing.T) {
+		args, err := input.NewInput([]string{"-plan=plan.json", "-identities=identities.yaml"})
+		assert.Equal(t, err, nil)
+		assert.Equal(t, args.Identities, "identities.yaml")
+	})
+
+	t.Run("Parses the directory path", func(t *testing.T) {
+		args, err := input.NewInput([]string{"-plan=plan.json", "-directory=directory.json"})
+		assert.Equal(t, err, nil)
+		assert.Equal(t, args.Directory, "directory.json")
+	})
+
+	t.Run("Parses strict mode", func(t *testing.T) {
+		args, err := input.NewInput([]string{"-plan=plan.json", "-strict"})
+		assert.Equal(t, err, nil)
+		assert.Equal(t, args.Strict, true)
+	})
+
 	t.Run("Returns error if path is not provided", func(t *testing.T) {
 		_, err := input.NewInput([]string{"-requester=alice", "-approvers=bob"})
 		assert.Equal(t, err.Error(), "plan is a required argument")
@@ -0,0 +1,125 @@
+package test
+
+import (
+	"aiven/terraform/governance/compliance/checker/internal/policy"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPolicy_Load(t *testing.T) {
+
+	t.Run("Reads a YAML policy", func(t *testing.T) {
+		checkPolicy, err := policy.Load("../testdata/policy_sandbox.yaml")
+		assert.Nil(t, err)
+		assert.Len(t, checkPolicy.Rules, 2)
+		assert.Equal(t, "testproject-*", checkPolicy.Rules[0].Project)
+		assert.Equal(t, policy.Params{"keys": "team,env"}, checkPolicy.Rules[0].Checks[1].Params)
+	})
+
+	t.Run("Reads a JSON policy", func(t *testing.T) {
+		checkPolicy, err := policy.Load("../testdata/policy_unknown_check.json")
+		assert.Nil(t, err)
+		assert.Equal(t, "change_is_approved_by_everyone", checkPolicy.Rules[0].Checks[0].Name)
+	})
+
+	t.Run("Returns error if path does not point to a file", func(t *testing.T) {
+		checkPolicy, err := policy.Load("not-a-file")
+		assert.Nil(t, checkPolicy)
+		assert.Equal(t, "invalid policy file", err.Error())
+	})
+
+	t.Run("Returns error for unknown fields", func(t *testing.T) {
+		checkPolicy, err := policy.Load("../testdata/plan_with_known_owner_user_group_id.json")
+		assert.Nil(t, checkPolicy)
+		assert.ErrorContains(t, err, "invalid policy file")
+	})
+}
+
+func TestPolicy_Validate(t *testing.T) {
+	definitions := policy.Definitions{
+		"change_is_requested_by_owner": {},
+		"required_tags":                {"keys"},
+	}
+
+	tests := []struct {
+		name     string
+		policy   policy.Policy
+		expected string
+	}{
+		{
+			name: "Valid policy",
+			policy: policy.Policy{Rules: []policy.Rule{
+				{ResourceType: "aiven_kafka_topic", Checks: []policy.Check{
+					{Name: "required_tags", Params: policy.Params{"keys": "team"}},
+				}},
+			}},
+		},
+		{
+			name:     "Resource type is required",
+			policy:   policy.Policy{Rules: []policy.Rule{{}}},
+			expected: "invalid policy: rule 1: resource_type is required",
+		},
+		{
+			name:     "Selectors must be valid patterns",
+			policy:   policy.Policy{Rules: []policy.Rule{{ResourceType: "aiven_kafka_topic", Project: "[sandbox"}}},
+			expected: `invalid policy: rule 1: invalid selector "[sandbox"`,
+		},
+		{
+			name: "Checks must be known",
+			policy: policy.Policy{Rules: []policy.Rule{
+				{ResourceType: "aiven_kafka_topic", Checks: []policy.Check{{Name: "unknown"}}},
+			}},
+			expected: `invalid policy: rule 1: unknown check "unknown"`,
+		},
+		{
+			name: "Checks are listed once per rule",
+			policy: policy.Policy{Rules: []policy.Rule{
+				{ResourceType: "aiven_kafka_topic", Checks: []policy.Check{
+					{Name: "change_is_requested_by_owner"}, {Name: "change_is_requested_by_owner"},
+				}},
+			}},
+			expected: `invalid policy: rule 1: check "change_is_requested_by_owner" is listed more than once`,
+		},
+		{
+			name: "Parameters must be accepted by the check",
+			policy: policy.Policy{Rules: []policy.Rule{
+				{ResourceType: "aiven_kafka_topic", Checks: []policy.Check{
+					{Name: "change_is_requested_by_owner", Params: policy.Params{"keys": "team"}},
+				}},
+			}},
+			expected: `invalid policy: rule 1: check "change_is_requested_by_owner" does not accept parameter "keys"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Validate(definitions)
+			if tt.expected == "" {
+				assert.Nil(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.expected)
+		})
+	}
+}
+
+func TestPolicy_Match(t *testing.T) {
+	checkPolicy, err := policy.Load("../testdata/policy_sandbox.yaml")
+	assert.Nil(t, err)
+
+	t.Run("Returns the first rule matching the selectors", func(t *testing.T) {
+		rule := checkPolicy.Match("aiven_kafka_topic", "testproject-hpo9", "kafka1")
+		assert.Equal(t, &checkPolicy.Rules[0], rule)
+	})
+
+	t.Run("Falls through to rules without selectors", func(t *testing.T) {
+		rule := checkPolicy.Match("aiven_kafka_topic", "production", "kafka1")
+		assert.Equal(t, &checkPolicy.Rules[1], rule)
+	})
+
+	t.Run("Returns nil if no rule matches the resource type", func(t *testing.T) {
+		rule := checkPolicy.Match("aiven_kafka_acl", "testproject-hpo9", "kafka1")
+		assert.Nil(t, rule)
+	})
+}
@@ -2,6 +2,8 @@ package test
 
 import (
 	"aiven/terraform/governance/compliance/checker/internal/terraform"
+	"encoding/json"
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -30,3 +32,338 @@ func TestTerraform_NewPlan(t *testing.T) {
 	})
 
 }
+
+func TestTerraform_Modules(t *testing.T) {
+	plan, err := terraform.NewPlan("../testdata/plan_with_modules.json")
+	assert.Nil(t, err)
+
+	t.Run("Returns prior state resources of child modules", func(t *testing.T) {
+		addresses := []string{}
+		for _, resource := range plan.PriorStateResources() {
+			addresses = append(addresses, resource.Address)
+		}
+		assert.Contains(t, addresses, "data.aiven_external_identity.alice")
+		assert.Contains(t, addresses, "module.payments.aiven_kafka_topic.orders")
+		assert.Contains(t, addresses, "module.team.aiven_organization_user_group_member.bob")
+	})
+
+	t.Run("Returns configuration resources of called modules with their module address", func(t *testing.T) {
+		addresses := []string{}
+		for _, resource := range plan.ConfigurationResources() {
+			addresses = append(addresses, resource.AbsoluteAddress())
+		}
+		assert.Contains(t, addresses, "data.aiven_organization_user.foo")
+		assert.Contains(t, addresses, "module.payments.aiven_kafka_topic.refunds")
+		assert.Contains(t, addresses, "module.team.aiven_organization_user_group.new")
+	})
+
+	t.Run("Resolves references to resources in the same module", func(t *testing.T) {
+		reference, err := plan.ResolveExpression(terraform.Scope{Module: "module.team"}, &terraform.Expression{
+			References: []string{"aiven_organization_user_group.this.group_id", "aiven_organization_user_group.this"},
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, "module.team.aiven_organization_user_group.this", reference.Address)
+	})
+
+	t.Run("Resolves input variables through the calling module", func(t *testing.T) {
+		reference, err := plan.ResolveExpression(terraform.Scope{Module: "module.team"}, &terraform.Expression{
+			References: []string{"var.alice_user_id"},
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, "data.aiven_organization_user.foo", reference.Address)
+	})
+
+	t.Run("Resolves input variables bound to outputs of another module", func(t *testing.T) {
+		reference, err := plan.ResolveExpression(terraform.Scope{Module: "module.payments"}, &terraform.Expression{
+			References: []string{"var.owner_user_group_id"},
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, "module.team.aiven_organization_user_group.this", reference.Address)
+	})
+
+	t.Run("Does not resolve unknown variables", func(t *testing.T) {
+		_, err := plan.ResolveExpression(terraform.Scope{Module: "module.payments"}, &terraform.Expression{
+			References: []string{"var.nonexistent"},
+		})
+		assert.ErrorIs(t, err, terraform.ErrUnknownVariable)
+	})
+}
+
+func TestTerraform_ResolveExpression(t *testing.T) {
+	plan, err := terraform.NewPlan("../testdata/plan_with_unresolvable_owner_user_group_id.json")
+	assert.Nil(t, err)
+
+	tests := []struct {
+		name       string
+		scope      terraform.Scope
+		expression *terraform.Expression
+		expected   *terraform.Reference
+		err        error
+	}{
+		{
+			name:       "Returns error for nil expressions",
+			expression: nil,
+			err:        terraform.ErrEmptyExpression,
+		},
+		{
+			name:       "Returns constant values",
+			expression: &terraform.Expression{ConstantValue: "ug4e3b20cee48"},
+			expected:   &terraform.Reference{Kind: terraform.ConstantReference, Value: "ug4e3b20cee48"},
+		},
+		{
+			name: "Returns data sources",
+			expression: &terraform.Expression{
+				References: []string{"data.aiven_organization_user.bar.user_id", "data.aiven_organization_user.bar"},
+			},
+			expected: &terraform.Reference{Kind: terraform.ResourceReference, Address: "data.aiven_organization_user.bar"},
+		},
+		{
+			name: "Returns the most specific indexed reference",
+			expression: &terraform.Expression{
+				References: []string{
+					`aiven_organization_user_group.g["payments"].group_id`,
+					`aiven_organization_user_group.g["payments"]`,
+					"aiven_organization_user_group.g",
+				},
+			},
+			expected: &terraform.Reference{
+				Kind:    terraform.ResourceReference,
+				Address: `aiven_organization_user_group.g["payments"]`,
+			},
+		},
+		{
+			name:  "Applies the instance key of the scope to repeated resources",
+			scope: terraform.Scope{Key: "payments"},
+			expression: &terraform.Expression{
+				References: []string{"aiven_organization_user_group.g", "each.key"},
+			},
+			expected: &terraform.Reference{
+				Kind:    terraform.ResourceReference,
+				Address: `aiven_organization_user_group.g["payments"]`,
+			},
+		},
+		{
+			// the references of g[upper(each.key)] are the same as those of g[each.key]
+			name:  "Assumes each.key selects the instance of the same key even when it is transformed",
+			scope: terraform.Scope{Key: "payments"},
+			expression: &terraform.Expression{
+				References: []string{"aiven_organization_user_group.g", "each.key"},
+			},
+			expected: &terraform.Reference{
+				Kind:    terraform.ResourceReference,
+				Address: `aiven_organization_user_group.g["payments"]`,
+			},
+		},
+		{
+			name:  "Returns error for instances of repeated resources selected by a dynamic key",
+			scope: terraform.Scope{Key: "payments"},
+			expression: &terraform.Expression{
+				References: []string{"aiven_organization_user_group.g", "each.value.team", "each.value"},
+			},
+			err: terraform.ErrUnknownInstance,
+		},
+		{
+			name: "Returns error for repeated resources without an instance key",
+			expression: &terraform.Expression{
+				References: []string{"aiven_organization_user_group.g.group_id", "aiven_organization_user_group.g"},
+			},
+			err: terraform.ErrUnknownInstance,
+		},
+		{
+			name:  "Does not apply the instance key to resources that are not repeated",
+			scope: terraform.Scope{Key: float64(1)},
+			expression: &terraform.Expression{
+				References: []string{"data.aiven_organization_user.bar.user_id", "data.aiven_organization_user.bar", "count.index"},
+			},
+			expected: &terraform.Reference{Kind: terraform.ResourceReference, Address: "data.aiven_organization_user.bar"},
+		},
+		{
+			name:       "Resolves root module variables from the plan",
+			expression: &terraform.Expression{References: []string{"var.owner_user_group_id"}},
+			expected:   &terraform.Reference{Kind: terraform.ConstantReference, Value: "ug4e3b20cee48"},
+		},
+		{
+			name:       "Returns error for locals",
+			expression: &terraform.Expression{References: []string{"local.owner_user_group_id"}},
+			err:        terraform.ErrUnsupportedReference,
+		},
+		{
+			name:       "Returns error for references that are not resources",
+			expression: &terraform.Expression{References: []string{"count.index"}},
+			err:        terraform.ErrNoResourceReference,
+		},
+		{
+			name:       "Returns error for invalid references",
+			expression: &terraform.Expression{References: []string{`aiven_organization_user_group.g["payments`}},
+			err:        terraform.ErrInvalidReference,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reference, resolveErr := plan.ResolveExpression(tt.scope, tt.expression)
+			if tt.err != nil {
+				assert.ErrorIs(t, resolveErr, tt.err)
+				assert.Nil(t, reference)
+				return
+			}
+			assert.Nil(t, resolveErr)
+			assert.Equal(t, tt.expected, reference)
+		})
+	}
+}
+
+func TestTerraform_ParseAddress(t *testing.T) {
+	tests := []struct {
+		name     string
+		address  string
+		expected terraform.Address
+	}{
+		{
+			name:    "Parses managed resources",
+			address: "aiven_kafka_topic.foo",
+			expected: terraform.Address{
+				Mode: terraform.ManagedResourceMode,
+				Type: terraform.AivenKafkaTopic,
+				Name: "foo",
+			},
+		},
+		{
+			name:    "Parses count instances",
+			address: "aiven_kafka_topic.bar[2]",
+			expected: terraform.Address{
+				Mode: terraform.ManagedResourceMode,
+				Type: terraform.AivenKafkaTopic,
+				Name: "bar",
+				Key:  float64(2),
+			},
+		},
+		{
+			name:    "Parses for_each instances of data sources",
+			address: `data.aiven_external_identity.users["alice.smith"]`,
+			expected: terraform.Address{
+				Mode: terraform.DataResourceMode,
+				Type: terraform.AivenExternalIdentity,
+				Name: "users",
+				Key:  "alice.smith",
+			},
+		},
+		{
+			name:    "Parses module instances",
+			address: `module.team["billing"].module.topics[0].aiven_kafka_topic.t["orders"]`,
+			expected: terraform.Address{
+				Module: []terraform.ModuleInstance{{Name: "team", Key: "billing"}, {Name: "topics", Key: float64(0)}},
+				Mode:   terraform.ManagedResourceMode,
+				Type:   terraform.AivenKafkaTopic,
+				Name:   "t",
+				Key:    "orders",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			address, err := terraform.ParseAddress(tt.address)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expected, address)
+			assert.Equal(t, tt.address, address.String())
+		})
+	}
+
+	t.Run("Returns the module address used by the configuration", func(t *testing.T) {
+		address, err := terraform.ParseAddress(`module.team["billing"].module.topics[0].aiven_kafka_topic.t["orders"]`)
+		assert.Nil(t, err)
+		assert.Equal(t, "module.team.module.topics", address.ConfigurationModule())
+		assert.Equal(t, `module.team["billing"].module.topics[0]`, address.ModuleAddress())
+	})
+
+	t.Run("Returns error for invalid addresses", func(t *testing.T) {
+		addresses := []string{"", "aiven_kafka_topic", "aiven_kafka_topic.foo.topic_name", `aiven_kafka_topic.foo["bar`}
+		for _, invalid := range addresses {
+			_, err := terraform.ParseAddress(invalid)
+			assert.ErrorIs(t, err, terraform.ErrInvalidAddress, invalid)
+		}
+	})
+}
+
+func TestTerraform_Unknown(t *testing.T) {
+	tests := []struct {
+		name         string
+		afterUnknown string
+		expected     terraform.Unknown
+	}{
+		{name: "Blocks known after apply are unknown", afterUnknown: `{"access_data": true}`, expected: true},
+		{
+			name:         "Blocks with some values known after apply are not unknown",
+			afterUnknown: `{"access_data": [{"acls": [{"id": true}]}]}`,
+			expected:     false,
+		},
+		{name: "Blocks known in the plan are not unknown", afterUnknown: `{}`, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var afterUnknown terraform.AfterUnknown
+			assert.Nil(t, json.Unmarshal([]byte(tt.afterUnknown), &afterUnknown))
+			assert.Equal(t, tt.expected, afterUnknown.AccessData)
+		})
+	}
+}
+
+func TestTerraform_ChangeKind(t *testing.T) {
+	tests := []struct {
+		actions  []terraform.ActionType
+		expected terraform.ChangeKind
+	}{
+		{actions: []terraform.ActionType{terraform.CreateAction}, expected: terraform.CreateChange},
+		{actions: []terraform.ActionType{terraform.UpdateAction}, expected: terraform.UpdateChange},
+		{actions: []terraform.ActionType{terraform.DeleteAction}, expected: terraform.DeleteChange},
+		{actions: []terraform.ActionType{terraform.DeleteAction, terraform.CreateAction}, expected: terraform.ReplaceChange},
+		{actions: []terraform.ActionType{terraform.CreateAction, terraform.DeleteAction}, expected: terraform.ReplaceChange},
+		{actions: []terraform.ActionType{terraform.NoOpAction}, expected: terraform.NoOpChange},
+		{actions: []terraform.ActionType{terraform.ReadAction}, expected: terraform.ReadChange},
+		{actions: []terraform.ActionType{terraform.ForgetAction}, expected: terraform.ForgetChange},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%v is a %s change", tt.actions, tt.expected), func(t *testing.T) {
+			change := terraform.Change{Actions: tt.actions}
+			assert.Equal(t, tt.expected, change.Kind())
+		})
+	}
+}
+
+func TestTerraform_Sources(t *testing.T) {
+	plan, err := terraform.NewPlan("../testdata/plan_with_modules.json")
+	assert.Nil(t, err)
+	sources := terraform.NewSources("../testdata/config/plan_with_modules", plan)
+
+	t.Run("Finds resources in the root module", func(t *testing.T) {
+		location, err := sources.Find("data.aiven_external_identity.bob")
+		assert.Nil(t, err)
+		assert.Equal(t, &terraform.SourceLocation{File: "../testdata/config/plan_with_modules/main.tf", Line: 15}, location)
+	})
+
+	t.Run("Follows local module sources", func(t *testing.T) {
+		location, err := sources.Find("module.payments.aiven_kafka_topic.refunds")
+		assert.Nil(t, err)
+		assert.Equal(t, &terraform.SourceLocation{
+			File: "../testdata/config/plan_with_modules/modules/topic/main.tf",
+			Line: 20,
+		}, location)
+	})
+
+	t.Run("Finds resources of other called modules", func(t *testing.T) {
+		location, err := sources.Find("module.team.aiven_organization_user_group_member.new_bob")
+		assert.Nil(t, err)
+		assert.Equal(t, 27, location.Line)
+	})
+
+	t.Run("Returns error if the resource is not declared", func(t *testing.T) {
+		_, err := sources.Find("aiven_kafka_topic.missing")
+		assert.ErrorIs(t, err, terraform.ErrSourceNotFound)
+
+		_, err = sources.Find("module.missing.aiven_kafka_topic.orders")
+		assert.ErrorIs(t, err, terraform.ErrUnknownModule)
+	})
+}
@@ -0,0 +1,35 @@
+data "aiven_organization_user" "foo" {
+  user_email = "alice@example.com"
+}
+
+data "aiven_organization_user" "bar" {
+  user_email = "bob@example.com"
+}
+
+data "aiven_external_identity" "alice" {
+  external_service_name = "github"
+  external_user_id      = "alice"
+  internal_user_id      = data.aiven_organization_user.foo.user_id
+}
+
+data "aiven_external_identity" "bob" {
+  external_service_name = "github"
+  external_user_id      = "bob"
+  internal_user_id      = data.aiven_organization_user.bar.user_id
+}
+
+module "team" {
+  source = "./modules/team"
+
+  alice_user_id = data.aiven_organization_user.foo.user_id
+  bob_user_id   = data.aiven_organization_user.bar.user_id
+}
+
+module "payments" {
+  source = "./modules/topic"
+
+  owner_user_group_id     = module.team.group_id
+  new_owner_user_group_id = module.team.new_group_id
+  project                 = "testproject-hpo9"
+  service_name            = "kafka1"
+}
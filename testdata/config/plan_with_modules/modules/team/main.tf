@@ -0,0 +1,38 @@
+variable "alice_user_id" {}
+variable "bob_user_id" {}
+
+resource "aiven_organization_user_group" "this" {
+  name = "payments"
+}
+
+resource "aiven_organization_user_group" "new" {
+  name = "refunds"
+}
+
+resource "aiven_organization_user_group_member" "alice" {
+  group_id = aiven_organization_user_group.this.group_id
+  user_id  = var.alice_user_id
+}
+
+resource "aiven_organization_user_group_member" "bob" {
+  group_id = aiven_organization_user_group.this.group_id
+  user_id  = var.bob_user_id
+}
+
+resource "aiven_organization_user_group_member" "new_alice" {
+  group_id = aiven_organization_user_group.new.group_id
+  user_id  = var.alice_user_id
+}
+
+resource "aiven_organization_user_group_member" "new_bob" {
+  group_id = aiven_organization_user_group.new.group_id
+  user_id  = var.bob_user_id
+}
+
+output "group_id" {
+  value = aiven_organization_user_group.this.group_id
+}
+
+output "new_group_id" {
+  value = aiven_organization_user_group.new.group_id
+}
@@ -0,0 +1,27 @@
+variable "owner_user_group_id" {}
+variable "new_owner_user_group_id" {}
+variable "project" {}
+variable "service_name" {}
+
+resource "aiven_kafka_topic" "orders" {
+  project             = var.project
+  service_name        = var.service_name
+  topic_name          = "orders"
+  owner_user_group_id = var.owner_user_group_id
+  partitions          = 3
+  replication         = 2
+
+  tag {
+    key   = "team"
+    value = "payments"
+  }
+}
+
+resource "aiven_kafka_topic" "refunds" {
+  project             = var.project
+  service_name        = var.service_name
+  topic_name          = "refunds"
+  owner_user_group_id = var.new_owner_user_group_id
+  partitions          = 3
+  replication         = 2
+}